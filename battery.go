@@ -0,0 +1,110 @@
+package shutd
+
+import (
+	"time"
+)
+
+const (
+	batteryTag                 = "battery"
+	defaultBatteryPollInterval = time.Minute
+)
+
+// BatteryConfig for shutting down laptop on low battery
+type BatteryConfig struct {
+	// ShutdownBelow percent while discharging to begin shutdown regardless of start time, 0 to disable
+	ShutdownBelow int
+	// SkipWhileCharging skips the scheduled shutdown while on AC and charging
+	SkipWhileCharging bool
+	// PollInterval of reading battery state, defaults to 1m
+	PollInterval time.Duration
+}
+
+func (c BatteryConfig) enabled() bool {
+	return c.ShutdownBelow > 0 || c.SkipWhileCharging
+}
+
+// BatteryState of the computer
+type BatteryState struct {
+	// Percent of remaining capacity
+	Percent     int
+	OnAC        bool
+	Charging    bool
+	Discharging bool
+}
+
+// Battery reads battery state of the computer
+type Battery interface {
+	ReadBattery() (BatteryState, error)
+}
+
+// WithBattery option to allow passing of custom battery, used when Config.Battery is set
+func WithBattery(b Battery) option {
+	return func(s *Scheduler) {
+		s.battery = b
+	}
+}
+
+// scheduleBatteryJob to poll battery state, the job is removed when battery config is not set.
+// The trigger is reset only when the config is changed from previous
+func (s *Scheduler) scheduleBatteryJob(previous BatteryConfig) error {
+	if s.config.Battery != previous {
+		s.batteryLow = false
+	}
+	interval := s.config.Battery.PollInterval
+	if interval <= 0 {
+		interval = defaultBatteryPollInterval
+	}
+	return s.scheduleMonitorJob(batteryTag, s.config.Battery.enabled(), interval, s.checkBattery)
+}
+
+// checkBattery begins shutdown when discharging below threshold, and restores the schedule once charging again
+// unless the pending shutdown is requested by others. The shutdown protects the machine, so it is not snoozable
+// and it is run regardless of pause and skip
+func (s *Scheduler) checkBattery() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	state, err := s.battery.ReadBattery()
+	if err != nil {
+		s.logEntry(batteryTag).WithError(err).Error("failed to read battery")
+		return
+	}
+	log := s.logEntry(batteryTag).WithField("battery", state.Percent)
+	threshold := s.config.Battery.ShutdownBelow
+	switch {
+	case !s.batteryLow && state.Discharging && state.Percent < threshold:
+		s.batteryLow = true
+		log.Info("battery low: shutdown triggered")
+		err = s.trigger(ShutdownRequest{
+			Reason:   "battery low",
+			Source:   SourceBattery,
+			Deadline: s.now().Add(s.config.Notification.Before),
+		})
+	case s.batteryLow && !state.Discharging:
+		s.batteryLow = false
+		// other requests are kept, e.g. one-off shutdown or emergency shutdown triggered meanwhile
+		if s.request.Source != SourceBattery || s.upsTriggered || s.overheated {
+			break
+		}
+		log.Info("battery is no longer discharging: shutdown schedule restored")
+		err = s.restoreSchedule()
+	}
+	if err != nil {
+		log.WithError(err).Error("failed to reschedule shutdown")
+	}
+}
+
+// chargingSkip returns whether scheduled shutdown is skipped as battery is charging on AC
+func (s *Scheduler) chargingSkip() bool {
+	if !s.config.Battery.SkipWhileCharging {
+		return false
+	}
+	state, err := s.battery.ReadBattery()
+	if err != nil {
+		s.logEntry(batteryTag).WithError(err).Error("failed to read battery")
+		return false
+	}
+	return state.OnAC && state.Charging
+}
@@ -0,0 +1,70 @@
+//go:build linux
+
+package shutd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sysfsBattery reads battery state from power supply class of sysfs
+type sysfsBattery struct {
+	root string
+}
+
+// NewSysfsBattery creates Battery reading <root>/sys/class/power_supply, root is / except for testing
+func NewSysfsBattery(root string) Battery {
+	return sysfsBattery{root: root}
+}
+
+func defaultBattery() Battery {
+	return NewSysfsBattery("/")
+}
+
+func (b sysfsBattery) ReadBattery() (BatteryState, error) {
+	dir := filepath.Join(b.root, "sys", "class", "power_supply")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return BatteryState{}, fmt.Errorf("failed to read power supplies: %w", err)
+	}
+	var state BatteryState
+	batteries, percent := 0, 0
+	for _, e := range entries {
+		supply := filepath.Join(dir, e.Name())
+		switch readSysfs(supply, "type") {
+		case "Mains", "USB":
+			if readSysfs(supply, "online") == "1" {
+				state.OnAC = true
+			}
+		case "Battery":
+			capacity, err := strconv.Atoi(readSysfs(supply, "capacity"))
+			if err != nil {
+				continue
+			}
+			batteries++
+			percent += capacity
+			switch readSysfs(supply, "status") {
+			case "Charging":
+				state.Charging = true
+			case "Discharging":
+				state.Discharging = true
+			}
+		}
+	}
+	if batteries == 0 {
+		return BatteryState{}, fmt.Errorf("no battery found in %v", dir)
+	}
+	state.Percent = percent / batteries
+	return state, nil
+}
+
+func readSysfs(dir, name string) string {
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
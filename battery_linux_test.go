@@ -0,0 +1,46 @@
+package shutd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writePowerSupply(t *testing.T, root, name string, attrs map[string]string) {
+	dir := filepath.Join(root, "sys", "class", "power_supply", name)
+	err := os.MkdirAll(dir, 0755)
+	assert.NoError(t, err)
+	for k, v := range attrs {
+		err = os.WriteFile(filepath.Join(dir, k), []byte(v+"\n"), 0644)
+		assert.NoError(t, err)
+	}
+}
+
+func TestSysfsBattery(t *testing.T) {
+	root := t.TempDir()
+	writePowerSupply(t, root, "AC", map[string]string{"type": "Mains", "online": "0"})
+	writePowerSupply(t, root, "BAT0", map[string]string{"type": "Battery", "capacity": "30", "status": "Discharging"})
+	writePowerSupply(t, root, "BAT1", map[string]string{"type": "Battery", "capacity": "10", "status": "Discharging"})
+
+	state, err := NewSysfsBattery(root).ReadBattery()
+	assert.NoError(t, err)
+	assert.Equal(t, BatteryState{Percent: 20, Discharging: true}, state)
+
+	writePowerSupply(t, root, "AC", map[string]string{"online": "1"})
+	writePowerSupply(t, root, "BAT0", map[string]string{"status": "Charging"})
+	writePowerSupply(t, root, "BAT1", map[string]string{"status": "Full"})
+
+	state, err = NewSysfsBattery(root).ReadBattery()
+	assert.NoError(t, err)
+	assert.Equal(t, BatteryState{Percent: 20, OnAC: true, Charging: true}, state)
+}
+
+func TestSysfsBatteryWithoutBattery(t *testing.T) {
+	root := t.TempDir()
+	writePowerSupply(t, root, "AC", map[string]string{"type": "Mains", "online": "1"})
+
+	_, err := NewSysfsBattery(root).ReadBattery()
+	assert.EqualError(t, err, "no battery found in "+filepath.Join(root, "sys", "class", "power_supply"))
+}
@@ -0,0 +1,15 @@
+//go:build !linux && !windows
+
+package shutd
+
+import "fmt"
+
+type unsupportedBattery struct{}
+
+func defaultBattery() Battery {
+	return unsupportedBattery{}
+}
+
+func (unsupportedBattery) ReadBattery() (BatteryState, error) {
+	return BatteryState{}, fmt.Errorf("battery is not supported on this platform")
+}
@@ -0,0 +1,185 @@
+package shutd
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeBattery struct {
+	mu    sync.Mutex
+	state BatteryState
+}
+
+func (b *fakeBattery) ReadBattery() (BatteryState, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state, nil
+}
+
+func (b *fakeBattery) set(state BatteryState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = state
+}
+
+func waitShutdownScheduled(t *testing.T, events <-chan Event, match func(shutdownTime time.Time) bool) {
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case e := <-events:
+			if e.Type == ShutdownScheduled && match(e.ShutdownTime) {
+				return
+			}
+		case <-timeout:
+			t.Fatal("shutdown should be rescheduled")
+		}
+	}
+}
+
+func TestLowBatteryTriggersShutdown(t *testing.T) {
+	battery := &fakeBattery{state: BatteryState{Percent: 15, Discharging: true}}
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	s, err := getSchedulerWithConfig(t, config, WithBattery(battery))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+
+	config.Battery = BatteryConfig{ShutdownBelow: 20, PollInterval: 50 * time.Millisecond}
+	err = s.Configure(config)
+	assert.NoError(t, err)
+
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Before(time.Now().Add(config.Notification.Before + time.Second))
+	})
+
+	battery.set(BatteryState{Percent: 16, OnAC: true, Charging: true})
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Format("15:04") == config.StartTime
+	})
+}
+
+func TestLowBatteryShutdownWhilePaused(t *testing.T) {
+	requests := make(chan ShutdownRequest, 1)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		requests <- r
+		return nil
+	}
+	battery := &fakeBattery{state: BatteryState{Percent: 15, Discharging: true}}
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	config.Battery = BatteryConfig{ShutdownBelow: 20, PollInterval: time.Hour}
+	s, err := getSchedulerWithConfig(t, config, WithShutdownTask(shutdownTask), WithBattery(battery))
+	assert.NoError(t, err)
+	assert.NoError(t, s.Pause())
+
+	s.checkBattery()
+	r := s.ShutdownRequest()
+	assert.Equal(t, SourceBattery, r.Source)
+	assert.False(t, r.Snoozable)
+	assert.ErrorIs(t, s.Snooze(), ErrNotSnoozable)
+
+	// run the job now instead of waiting for the notice
+	s.runShutdownJob()
+	select {
+	case r := <-requests:
+		assert.Equal(t, SourceBattery, r.Source)
+		assert.Equal(t, "battery low", r.Reason)
+	default:
+		t.Fatal("shutdownTask should be called while paused")
+	}
+	assert.True(t, s.Paused())
+}
+
+func TestBatteryAboveThresholdDoesNotTriggerShutdown(t *testing.T) {
+	battery := &fakeBattery{state: BatteryState{Percent: 50, Discharging: true}}
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	s, err := getSchedulerWithConfig(t, config, WithBattery(battery))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+
+	config.Battery = BatteryConfig{ShutdownBelow: 20, PollInterval: 50 * time.Millisecond}
+	err = s.Configure(config)
+	assert.NoError(t, err)
+
+	timeout := time.After(200 * time.Millisecond)
+	for {
+		select {
+		case e := <-events:
+			assert.Equal(t, config.StartTime, e.ShutdownTime.Format("15:04"))
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func TestShutdownSkippedWhileCharging(t *testing.T) {
+	called := make(chan bool, 1)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		called <- true
+		return nil
+	}
+	battery := &fakeBattery{state: BatteryState{Percent: 80, OnAC: true, Charging: true}}
+	config := getConfigWithShutdownTime(time.Now().Add(1 * time.Second).Format("15:04:05"))
+	config.Battery = BatteryConfig{SkipWhileCharging: true, PollInterval: time.Hour}
+	_, err := getSchedulerWithConfig(t, config, WithShutdownTask(shutdownTask), WithBattery(battery))
+	assert.NoError(t, err)
+
+	select {
+	case <-called:
+		t.Fatal("shutdownTask should be skipped while charging")
+	case <-time.After(2 * time.Second):
+	}
+}
+
+func TestTriggeredShutdownInDryRunRestoresSchedule(t *testing.T) {
+	battery := &fakeBattery{state: BatteryState{Percent: 15, Discharging: true}}
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	config.DryRun = true
+	config.Battery = BatteryConfig{ShutdownBelow: 20, PollInterval: time.Hour}
+	s, err := getSchedulerWithConfig(t, config, WithBattery(battery))
+	assert.NoError(t, err)
+
+	s.checkBattery()
+	assert.Equal(t, SourceBattery, s.ShutdownRequest().Source)
+	s.runShutdownJob()
+
+	shutdownTime, _ := s.ShutdownTime()
+	assert.Equal(t, config.StartTime, shutdownTime.Format("15:04"), "daily shutdown should not stay at the triggered time")
+	assert.Equal(t, SourceScheduled, s.ShutdownRequest().Source)
+	assert.False(t, s.batteryLow, "battery could trigger again")
+}
+
+func TestSnoozedShutdownInDryRunRestoresSchedule(t *testing.T) {
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	config.DryRun = true
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Snooze())
+	s.runShutdownJob()
+
+	shutdownTime, _ := s.ShutdownTime()
+	assert.Equal(t, config.StartTime, shutdownTime.Format("15:04"))
+	assert.Equal(t, 0, s.SnoozeCount())
+}
+
+func TestBatteryBackOnACKeepsOneOffShutdown(t *testing.T) {
+	battery := &fakeBattery{state: BatteryState{Percent: 15, Discharging: true}}
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	config.Battery = BatteryConfig{ShutdownBelow: 20, PollInterval: time.Hour}
+	s, err := getSchedulerWithConfig(t, config, WithBattery(battery))
+	assert.NoError(t, err)
+
+	at := time.Now().Add(3 * time.Minute).Truncate(time.Second)
+	assert.NoError(t, s.ShutdownAt(at))
+	s.checkBattery()
+	assert.True(t, s.batteryLow)
+
+	battery.set(BatteryState{Percent: 16, OnAC: true, Charging: true})
+	s.checkBattery()
+	assert.False(t, s.batteryLow)
+	assert.Equal(t, SourceOneOff, s.ShutdownRequest().Source)
+	shutdownTime, _ := s.ShutdownTime()
+	assert.True(t, at.Equal(shutdownTime), "one-off shutdown should be kept at %v, got %v", at, shutdownTime)
+}
@@ -0,0 +1,52 @@
+//go:build windows
+
+package shutd
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var procGetSystemPowerStatus = syscall.NewLazyDLL("kernel32.dll").NewProc("GetSystemPowerStatus")
+
+// systemPowerStatus of SYSTEM_POWER_STATUS structure
+type systemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+const (
+	batteryFlagCharging  = 8
+	batteryFlagNoBattery = 128
+	batteryFlagUnknown   = 255
+)
+
+// powerStatusBattery reads battery state via GetSystemPowerStatus
+type powerStatusBattery struct{}
+
+func defaultBattery() Battery {
+	return powerStatusBattery{}
+}
+
+func (powerStatusBattery) ReadBattery() (BatteryState, error) {
+	var status systemPowerStatus
+	r, _, err := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&status)))
+	if r == 0 {
+		return BatteryState{}, fmt.Errorf("failed to get system power status: %w", err)
+	}
+	if status.BatteryFlag == batteryFlagUnknown || status.BatteryFlag&batteryFlagNoBattery != 0 || status.BatteryLifePercent > 100 {
+		return BatteryState{}, fmt.Errorf("no battery found")
+	}
+	onAC := status.ACLineStatus == 1
+	return BatteryState{
+		Percent:     int(status.BatteryLifePercent),
+		OnAC:        onAC,
+		Charging:    status.BatteryFlag&batteryFlagCharging != 0,
+		Discharging: !onAC,
+	}, nil
+}
@@ -0,0 +1,179 @@
+package shutd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const skipDateLayout = "2006-01-02"
+
+// CalendarConfig to skip or shift shutdowns by events of iCalendar file, e.g. holidays
+type CalendarConfig struct {
+	// ICS path of local iCalendar file, it is read again once modified so changes apply without reload
+	ICS string
+	// SkipOnEventsMatching regular expression of event summary, e.g. "Vacation|Release night", empty to match all events
+	SkipOnEventsMatching string
+}
+
+// calendarDecision of the shutdown by skip dates and calendar events
+type calendarDecision struct {
+	Skip bool
+	// ShiftTo end of the event covering the shutdown time, zero if not shifted
+	ShiftTo time.Time
+	Reason  string
+}
+
+// calendarFile caches events of iCalendar file until it is modified, it has its own lock,
+// so the file is read without holding the lock of scheduler
+type calendarFile struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	size    int64
+	events  []calendarEvent
+}
+
+// read events of the file at path, it is only parsed again once modified
+func (f *calendarFile) read(path string) ([]calendarEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open calendar: %w", err)
+	}
+	if path == f.path && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.events, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open calendar: %w", err)
+	}
+	defer file.Close()
+	events, err := parseICS(file)
+	if err != nil {
+		return nil, err
+	}
+	f.path, f.modTime, f.size, f.events = path, info.ModTime(), info.Size(), events
+	return events, nil
+}
+
+// calendarCheck is the calendar decision for the shutdown at ShutdownTime, made before the job takes the lock
+type calendarCheck struct {
+	ShutdownTime time.Time
+	Decision     calendarDecision
+	Err          error
+}
+
+// checkCalendar for the shutdown at the time returned by shutdownTime under lock, the calendar is read
+// and evaluated without lock held. It is called without lock held
+func (s *Scheduler) checkCalendar(shutdownTime func() time.Time) calendarCheck {
+	s.mu.Lock()
+	config := s.config
+	t := shutdownTime()
+	s.mu.Unlock()
+	d, err := config.calendarDecision(t, &s.calendar)
+	return calendarCheck{ShutdownTime: t, Decision: d, Err: err}
+}
+
+// nightOf returns the date of night the shutdown belongs to, shutdowns before noon belong to the previous day
+func nightOf(t time.Time) time.Time {
+	night := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if t.Hour() < 12 {
+		night = night.AddDate(0, 0, -1)
+	}
+	return night
+}
+
+// calendarDecision for the shutdown at shutdownTime with events of calendar. The night is skipped on skip dates and all-day events,
+// while shutdown during timed event is shifted to its end, or skipped if the event lasts for more than a day
+func (c Config) calendarDecision(shutdownTime time.Time, calendar *calendarFile) (calendarDecision, error) {
+	night := nightOf(shutdownTime)
+	for _, d := range c.SkipDates {
+		if d == night.Format(skipDateLayout) {
+			return calendarDecision{Skip: true, Reason: fmt.Sprintf("skip date %v", d)}, nil
+		}
+	}
+	if c.Calendar.ICS == "" {
+		return calendarDecision{}, nil
+	}
+	match, err := regexp.Compile(c.Calendar.SkipOnEventsMatching)
+	if err != nil {
+		return calendarDecision{}, err
+	}
+	events, err := calendar.read(c.Calendar.ICS)
+	if err != nil {
+		return calendarDecision{}, err
+	}
+
+	var decision calendarDecision
+	for _, e := range events {
+		if !match.MatchString(e.Summary) {
+			continue
+		}
+		if e.AllDay {
+			if _, ok := e.covering(night); ok {
+				return calendarDecision{Skip: true, Reason: fmt.Sprintf("calendar event %q", e.Summary)}, nil
+			}
+			continue
+		}
+		end, ok := e.covering(shutdownTime)
+		if !ok {
+			continue
+		}
+		if end.Sub(shutdownTime) > 24*time.Hour {
+			return calendarDecision{Skip: true, Reason: fmt.Sprintf("calendar event %q", e.Summary)}, nil
+		}
+		if end.After(decision.ShiftTo) {
+			decision = calendarDecision{ShiftTo: end, Reason: fmt.Sprintf("shifted after calendar event %q", e.Summary)}
+		}
+	}
+	return decision, nil
+}
+
+// SkipReason returns why the shutdown at shutdownTime is skipped by Config.SkipDates or Config.Calendar
+func (s *Scheduler) SkipReason(shutdownTime time.Time) (string, bool) {
+	d, err := s.Config().calendarDecision(shutdownTime, &s.calendar)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to read calendar")
+		return "", false
+	}
+	return d.Reason, d.Skip
+}
+
+// calendarSkip returns whether scheduled shutdown at shutdownTime is skipped, or shifted to one-off shutdown after the event.
+// The decision of check is only applied if it is made for shutdownTime
+func (s *Scheduler) calendarSkip(log *logrus.Entry, check calendarCheck, shutdownTime time.Time) bool {
+	if check.Err != nil {
+		log.WithError(check.Err).Error("failed to read calendar")
+		return false
+	}
+	if !check.ShutdownTime.Equal(shutdownTime) {
+		log.Info("calendar: shutdown time is changed while reading calendar, it is checked on the next run")
+		return false
+	}
+	d := check.Decision
+	if d.Skip {
+		log.WithField("reason", d.Reason).Info("calendar: skipped")
+		return true
+	}
+	if d.ShiftTo.IsZero() {
+		return false
+	}
+	err := s.shutdownOnce(ShutdownRequest{
+		Reason:    d.Reason,
+		Source:    SourceCalendar,
+		Snoozable: true,
+		Deadline:  laterTime(d.ShiftTo, s.now().Add(minTriggerDelay)),
+	}, shutdownTime)
+	if err != nil {
+		log.WithError(err).Error("failed to shift shutdown")
+		return false
+	}
+	log.WithField("reason", d.Reason).Info("calendar: shutdown shifted")
+	return true
+}
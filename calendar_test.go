@@ -0,0 +1,182 @@
+package shutd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeICS(t *testing.T, events ...string) string {
+	content := "BEGIN:VCALENDAR\n"
+	for _, e := range events {
+		content += "BEGIN:VEVENT\n" + e + "END:VEVENT\n"
+	}
+	content += "END:VCALENDAR\n"
+	path := filepath.Join(t.TempDir(), "calendar.ics")
+	err := os.WriteFile(path, []byte(content), 0644)
+	assert.NoError(t, err)
+	return path
+}
+
+func icsEvent(summary string, start, end time.Time) string {
+	return "SUMMARY:" + summary + "\nDTSTART:" + start.UTC().Format("20060102T150405Z") + "\nDTEND:" + end.UTC().Format("20060102T150405Z") + "\n"
+}
+
+func TestNightOf(t *testing.T) {
+	assert.Equal(t, "2026-12-24", nightOf(time.Date(2026, 12, 25, 1, 0, 0, 0, time.Local)).Format(skipDateLayout))
+	assert.Equal(t, "2026-12-24", nightOf(time.Date(2026, 12, 24, 23, 0, 0, 0, time.Local)).Format(skipDateLayout))
+	assert.Equal(t, "2026-12-25", nightOf(time.Date(2026, 12, 25, 12, 0, 0, 0, time.Local)).Format(skipDateLayout))
+}
+
+func TestCalendarDecision(t *testing.T) {
+	shutdownTime := time.Date(2026, 12, 25, 1, 0, 0, 0, time.Local)
+	config := getDefaultConfig()
+	config.SkipDates = []string{"2026-12-24"}
+	d, err := config.calendarDecision(shutdownTime, &calendarFile{})
+	assert.NoError(t, err)
+	assert.Equal(t, calendarDecision{Skip: true, Reason: "skip date 2026-12-24"}, d)
+
+	config = getDefaultConfig()
+	config.Calendar = CalendarConfig{
+		ICS: writeICS(t,
+			"SUMMARY:Vacation\nDTSTART;VALUE=DATE:20261224\n",
+			icsEvent("Release night", shutdownTime.Add(-2*time.Hour), shutdownTime.Add(time.Hour)),
+			icsEvent("Dentist", shutdownTime.Add(-time.Hour), shutdownTime.Add(5*time.Hour)),
+		),
+		SkipOnEventsMatching: "Vacation|Release night",
+	}
+	d, err = config.calendarDecision(shutdownTime, &calendarFile{})
+	assert.NoError(t, err)
+	assert.Equal(t, calendarDecision{Skip: true, Reason: `calendar event "Vacation"`}, d)
+
+	d, err = config.calendarDecision(shutdownTime.AddDate(0, 0, 1), &calendarFile{})
+	assert.NoError(t, err)
+	assert.Equal(t, calendarDecision{}, d)
+
+	config.Calendar.SkipOnEventsMatching = "Release night"
+	d, err = config.calendarDecision(shutdownTime, &calendarFile{})
+	assert.NoError(t, err)
+	assert.Equal(t, `shifted after calendar event "Release night"`, d.Reason)
+	assert.False(t, d.Skip)
+	assert.True(t, shutdownTime.Add(time.Hour).Equal(d.ShiftTo))
+
+	// matching all events, all-day event takes precedence
+	config.Calendar.SkipOnEventsMatching = ""
+	d, err = config.calendarDecision(shutdownTime.AddDate(0, 0, 1), &calendarFile{})
+	assert.NoError(t, err)
+	assert.Equal(t, calendarDecision{}, d)
+	d, err = config.calendarDecision(shutdownTime, &calendarFile{})
+	assert.NoError(t, err)
+	assert.Equal(t, calendarDecision{Skip: true, Reason: `calendar event "Vacation"`}, d)
+}
+
+func TestCalendarDecisionSkipsLongTimedEvent(t *testing.T) {
+	shutdownTime := time.Date(2026, 12, 25, 1, 0, 0, 0, time.Local)
+	config := getDefaultConfig()
+	config.Calendar.ICS = writeICS(t, icsEvent("Trip", shutdownTime.Add(-time.Hour), shutdownTime.Add(48*time.Hour)))
+	d, err := config.calendarDecision(shutdownTime, &calendarFile{})
+	assert.NoError(t, err)
+	assert.Equal(t, calendarDecision{Skip: true, Reason: `calendar event "Trip"`}, d)
+
+	config.Calendar.ICS = filepath.Join(t.TempDir(), "missing.ics")
+	_, err = config.calendarDecision(shutdownTime, &calendarFile{})
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestCalendarFileIsReadAgainOnceModified(t *testing.T) {
+	shutdownTime := time.Date(2026, 12, 25, 1, 0, 0, 0, time.Local)
+	config := getDefaultConfig()
+	config.Calendar.ICS = writeICS(t, "SUMMARY:Vacation\nDTSTART;VALUE=DATE:20261224\n")
+	calendar := &calendarFile{}
+	d, err := config.calendarDecision(shutdownTime, calendar)
+	assert.NoError(t, err)
+	assert.True(t, d.Skip)
+
+	err = os.WriteFile(config.Calendar.ICS, []byte("BEGIN:VCALENDAR\nEND:VCALENDAR\n"), 0644)
+	assert.NoError(t, err)
+	modTime := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(config.Calendar.ICS, modTime, modTime))
+	d, err = config.calendarDecision(shutdownTime, calendar)
+	assert.NoError(t, err)
+	assert.False(t, d.Skip)
+	assert.Empty(t, calendar.events)
+}
+
+func TestInvalidCalendarConfig(t *testing.T) {
+	config := getDefaultConfig()
+	config.SkipDates = []string{"24/12"}
+	_, err := getSchedulerWithConfig(t, config)
+	assert.EqualError(t, err, `invalid skipDates "24/12": the given time format is not supported`)
+	assert.ErrorIs(t, err, ErrInvalidTime)
+
+	config = getDefaultConfig()
+	config.Calendar.SkipOnEventsMatching = "("
+	_, err = getSchedulerWithConfig(t, config)
+	var e *ConfigError
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "calendar.skipOnEventsMatching", e.Field)
+}
+
+func TestSkipReason(t *testing.T) {
+	config := getConfigWithShutdownTime(time.Now().Add(3 * time.Hour).Format("15:04"))
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+	shutdownTime, _ := s.ShutdownTime()
+	_, ok := s.SkipReason(shutdownTime)
+	assert.False(t, ok)
+
+	config.SkipDates = []string{nightOf(shutdownTime).Format(skipDateLayout)}
+	err = s.Configure(config)
+	assert.NoError(t, err)
+	reason, ok := s.SkipReason(shutdownTime)
+	assert.True(t, ok)
+	assert.Equal(t, "skip date "+config.SkipDates[0], reason)
+}
+
+func TestCalendarSkipsSnoozeNotification(t *testing.T) {
+	called := 0
+	notificationTask := func(s *Scheduler, r ShutdownRequest) error {
+		called++
+		return nil
+	}
+	config := getConfigWithShutdownTime(time.Now().Add(3 * time.Hour).Format("15:04"))
+	s, err := getSchedulerWithConfig(t, config, WithSnoozeNotificationTask(notificationTask))
+	assert.NoError(t, err)
+	shutdownTime, _ := s.ShutdownTime()
+
+	config.SkipDates = []string{nightOf(shutdownTime).Format(skipDateLayout)}
+	err = s.Configure(config)
+	assert.NoError(t, err)
+	s.runSnoozeNotificationJob()
+	assert.Equal(t, 0, called)
+	assert.Equal(t, SourceScheduled, s.ShutdownRequest().Source)
+}
+
+func TestCalendarShiftsShutdown(t *testing.T) {
+	called := 0
+	notificationTask := func(s *Scheduler, r ShutdownRequest) error {
+		called++
+		return nil
+	}
+	config := getConfigWithShutdownTime(time.Now().Add(3 * time.Hour).Format("15:04"))
+	s, err := getSchedulerWithConfig(t, config, WithSnoozeNotificationTask(notificationTask))
+	assert.NoError(t, err)
+	shutdownTime, _ := s.ShutdownTime()
+
+	end := shutdownTime.Add(time.Hour)
+	config.Calendar.ICS = writeICS(t, icsEvent("Release night", shutdownTime.Add(-time.Hour), end))
+	err = s.Configure(config)
+	assert.NoError(t, err)
+	s.runSnoozeNotificationJob()
+	assert.Equal(t, 0, called)
+
+	shifted, _ := s.ShutdownTime()
+	assert.True(t, end.Equal(shifted), "shutdown should be shifted to %v, got %v", end, shifted)
+	r := s.ShutdownRequest()
+	assert.Equal(t, SourceCalendar, r.Source)
+	assert.Equal(t, `shifted after calendar event "Release night"`, r.Reason)
+}
@@ -0,0 +1,105 @@
+package shutd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloseStopsJobs(t *testing.T) {
+	called := make(chan bool, 1)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		called <- true
+		return nil
+	}
+	config := getConfigWithShutdownTime(time.Now().Add(1 * time.Second).Format("15:04:05"))
+	s, err := getSchedulerWithConfig(t, config, WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+
+	err = s.Close(context.Background())
+	assert.NoError(t, err)
+	select {
+	case <-called:
+		t.Fatal("shutdownTask should not be called after close")
+	case <-time.After(2 * time.Second):
+	}
+
+	assert.ErrorIs(t, s.Close(context.Background()), ErrClosed)
+	assert.ErrorIs(t, s.Configure(config), ErrClosed)
+	assert.ErrorIs(t, s.Snooze(), ErrClosed)
+	assert.ErrorIs(t, s.Skip(), ErrClosed)
+	assert.ErrorIs(t, s.Pause(), ErrClosed)
+	assert.ErrorIs(t, s.Resume(), ErrClosed)
+	assert.ErrorIs(t, s.ShutdownNow(), ErrClosed)
+}
+
+func TestCloseCancelsNotification(t *testing.T) {
+	asked := make(chan bool, 1)
+	cancelled := make(chan error, 1)
+	notifier := NotifierFunc(func(ctx context.Context, title, text string) (bool, error) {
+		asked <- true
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return false, ctx.Err()
+	})
+	// notification is shown immediately as shutdown is within notification.before
+	config := getConfigWithShutdownTime(time.Now().Add(5 * time.Minute).Format("15:04:05"))
+	config.Notification.Notifier = "test"
+	s, err := getSchedulerWithConfig(t, config, WithNotifier("test", notifier), WithSnoozeNotificationTask(newNotificationSnoozeTask()))
+	assert.NoError(t, err)
+
+	select {
+	case <-asked:
+	case <-time.After(2 * time.Second):
+		t.Fatal("notification should be shown")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err = s.Close(ctx)
+	assert.NoError(t, err)
+	select {
+	case err := <-cancelled:
+		assert.ErrorIs(t, err, context.Canceled)
+	default:
+		t.Fatal("notification should be cancelled before Close returns")
+	}
+}
+
+func TestCloseWaitsForTasks(t *testing.T) {
+	started := make(chan bool)
+	release := make(chan bool)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		started <- true
+		<-release
+		return nil
+	}
+	s, err := getSchedulerWithConfig(t, getDefaultConfig(), WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+	go s.ShutdownNow()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = s.Close(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	close(release)
+}
+
+func TestCloseClosesEventChannels(t *testing.T) {
+	s := getScheduler(t)
+	events := s.Subscribe()
+	assert.NoError(t, s.Pause())
+
+	err := s.Close(context.Background())
+	assert.NoError(t, err)
+	e, ok := <-events
+	assert.True(t, ok)
+	assert.Equal(t, ShutdownPaused, e.Type)
+	_, ok = <-events
+	assert.False(t, ok)
+
+	_, ok = <-s.Subscribe()
+	assert.False(t, ok)
+}
@@ -0,0 +1,156 @@
+// Package autostart installs and removes the artifact that starts shutd when user logs in
+package autostart
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Method of autostart
+type Method string
+
+// Options of where the autostart artifacts are placed
+type Options struct {
+	// Home directory of the user
+	Home string
+	// ConfigDir is XDG_CONFIG_HOME on Linux and APPDATA on Windows
+	ConfigDir string
+	// Exe path of shutd executable
+	Exe string
+}
+
+// Status of autostart method
+type Status struct {
+	Method    Method
+	Installed bool
+	// Path of the artifact
+	Path string
+}
+
+type installer interface {
+	// install artifact and returns its path
+	install(exe string) (string, error)
+	uninstall() error
+	status() (Status, error)
+}
+
+// DefaultOptions for current user and executable
+func DefaultOptions() (Options, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Options{}, fmt.Errorf("failed to get home dir: %w", err)
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return Options{}, fmt.Errorf("failed to get config dir: %w", err)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return Options{}, fmt.Errorf("failed to get executable path: %w", err)
+	}
+	return Options{Home: home, ConfigDir: configDir, Exe: exe}, nil
+}
+
+// Install autostart with method, empty method uses the platform default, returns path of the artifact
+func Install(m Method, o Options) (string, error) {
+	i, err := newInstaller(m, o)
+	if err != nil {
+		return "", err
+	}
+	return i.install(o.Exe)
+}
+
+// Uninstall autostart with method, empty method uninstalls all methods
+func Uninstall(m Method, o Options) error {
+	methods := []Method{m}
+	if m == "" {
+		methods = Methods()
+	}
+	for _, m := range methods {
+		i, err := newInstaller(m, o)
+		if err != nil {
+			return err
+		}
+		err = i.uninstall()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check status of all methods on the platform
+func Check(o Options) ([]Status, error) {
+	var statuses []Status
+	for _, m := range Methods() {
+		i, err := newInstaller(m, o)
+		if err != nil {
+			return nil, err
+		}
+		s, err := i.status()
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+func newInstaller(m Method, o Options) (installer, error) {
+	methods := Methods()
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("autostart is not supported on this platform")
+	}
+	if m == "" {
+		m = methods[0]
+	}
+	factory, ok := installers[m]
+	if !ok {
+		return nil, fmt.Errorf("unknown autostart method: %v", m)
+	}
+	return factory(o), nil
+}
+
+// fileInstaller writes content into file
+type fileInstaller struct {
+	method  Method
+	path    string
+	content func(exe string) string
+}
+
+func (f fileInstaller) install(exe string) (string, error) {
+	err := os.MkdirAll(filepath.Dir(f.path), 0755)
+	if err != nil {
+		return "", fmt.Errorf("failed to create autostart dir: %w", err)
+	}
+	err = os.WriteFile(f.path, []byte(f.content(exe)), 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to write autostart file: %w", err)
+	}
+	return f.path, nil
+}
+
+func (f fileInstaller) uninstall() error {
+	err := os.Remove(f.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove autostart file: %w", err)
+	}
+	return nil
+}
+
+func (f fileInstaller) status() (Status, error) {
+	return fileStatus(f.method, f.path)
+}
+
+func fileStatus(m Method, path string) (Status, error) {
+	_, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Status{Method: m, Path: path}, nil
+		}
+		return Status{}, fmt.Errorf("failed to check autostart file: %w", err)
+	}
+	return Status{Method: m, Installed: true, Path: path}, nil
+}
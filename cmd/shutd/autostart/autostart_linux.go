@@ -0,0 +1,125 @@
+//go:build linux
+
+package autostart
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// XDG autostart desktop entry, started with graphical session so systray is available
+	XDG Method = "xdg"
+	// Systemd user service running headless
+	Systemd Method = "systemd"
+)
+
+var installers = map[Method]func(o Options) installer{
+	XDG: func(o Options) installer {
+		return fileInstaller{method: XDG, path: filepath.Join(configDir(o), "autostart", "shutd.desktop"), content: desktopEntry}
+	},
+	Systemd: func(o Options) installer {
+		return systemdInstaller{dir: filepath.Join(configDir(o), "systemd", "user")}
+	},
+}
+
+// Methods supported on the platform, the first one is default
+func Methods() []Method {
+	return []Method{XDG, Systemd}
+}
+
+func configDir(o Options) string {
+	if o.ConfigDir != "" {
+		return o.ConfigDir
+	}
+	return filepath.Join(o.Home, ".config")
+}
+
+func desktopEntry(exe string) string {
+	return fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=Shutd
+Comment=Auto shutdown utility
+Exec="%v"
+Terminal=false
+X-GNOME-Autostart-enabled=true
+`, exe)
+}
+
+func systemdUnit(exe string) string {
+	return fmt.Sprintf(`[Unit]
+Description=Shutd auto shutdown utility
+
+[Service]
+Type=notify
+ExecStart="%v" --headless
+Restart=on-failure
+WatchdogSec=60
+
+[Install]
+WantedBy=default.target
+`, exe)
+}
+
+// systemdInstaller writes user unit and enables it by linking into default.target.wants, same as systemctl --user enable
+type systemdInstaller struct {
+	dir string
+}
+
+func (s systemdInstaller) unitPath() string {
+	return filepath.Join(s.dir, "shutd.service")
+}
+
+func (s systemdInstaller) wantsPath() string {
+	return filepath.Join(s.dir, "default.target.wants", "shutd.service")
+}
+
+func (s systemdInstaller) install(exe string) (string, error) {
+	unit := fileInstaller{method: Systemd, path: s.unitPath(), content: systemdUnit}
+	path, err := unit.install(exe)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(filepath.Dir(s.wantsPath()), 0755)
+	if err != nil {
+		return "", fmt.Errorf("failed to create systemd wants dir: %w", err)
+	}
+	err = os.Remove(s.wantsPath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to remove existing systemd unit link: %w", err)
+	}
+	err = os.Symlink(s.unitPath(), s.wantsPath())
+	if err != nil {
+		return "", fmt.Errorf("failed to enable systemd unit: %w", err)
+	}
+	return path, nil
+}
+
+func (s systemdInstaller) uninstall() error {
+	for _, path := range []string{s.wantsPath(), s.unitPath()} {
+		err := os.Remove(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove systemd unit: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s systemdInstaller) status() (Status, error) {
+	status, err := fileStatus(Systemd, s.unitPath())
+	if err != nil || !status.Installed {
+		return status, err
+	}
+	// unit without link is not enabled
+	_, err = os.Lstat(s.wantsPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			status.Installed = false
+			return status, nil
+		}
+		return Status{}, fmt.Errorf("failed to check systemd unit link: %w", err)
+	}
+	return status, nil
+}
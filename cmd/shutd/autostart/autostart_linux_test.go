@@ -0,0 +1,94 @@
+package autostart
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tempHomeOptions(t *testing.T) Options {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	o, err := DefaultOptions()
+	assert.NoError(t, err)
+	o.Exe = "/opt/shutd/shutd"
+	return o
+}
+
+func TestDefaultOptionsUsesHome(t *testing.T) {
+	o := tempHomeOptions(t)
+	assert.Equal(t, filepath.Join(o.Home, ".config"), o.ConfigDir)
+}
+
+func TestInstallXDG(t *testing.T) {
+	o := tempHomeOptions(t)
+	path, err := Install("", o)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(o.Home, ".config", "autostart", "shutd.desktop"), path)
+
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `Exec="/opt/shutd/shutd"`)
+
+	statuses, err := Check(o)
+	assert.NoError(t, err)
+	assert.Equal(t, []Status{
+		{Method: XDG, Installed: true, Path: path},
+		{Method: Systemd, Installed: false, Path: filepath.Join(o.Home, ".config", "systemd", "user", "shutd.service")},
+	}, statuses)
+
+	err = Uninstall(XDG, o)
+	assert.NoError(t, err)
+	assert.NoFileExists(t, path)
+}
+
+func TestInstallSystemd(t *testing.T) {
+	o := tempHomeOptions(t)
+	path, err := Install(Systemd, o)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(o.Home, ".config", "systemd", "user", "shutd.service"), path)
+
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `ExecStart="/opt/shutd/shutd" --headless`)
+
+	link := filepath.Join(o.Home, ".config", "systemd", "user", "default.target.wants", "shutd.service")
+	target, err := os.Readlink(link)
+	assert.NoError(t, err)
+	assert.Equal(t, path, target)
+
+	// reinstall should replace the existing link
+	_, err = Install(Systemd, o)
+	assert.NoError(t, err)
+
+	statuses, err := Check(o)
+	assert.NoError(t, err)
+	assert.False(t, statuses[0].Installed)
+	assert.True(t, statuses[1].Installed)
+
+	err = Uninstall("", o)
+	assert.NoError(t, err)
+	assert.NoFileExists(t, path)
+	assert.NoFileExists(t, link)
+}
+
+func TestSystemdUnitWithoutLinkIsNotInstalled(t *testing.T) {
+	o := tempHomeOptions(t)
+	path, err := Install(Systemd, o)
+	assert.NoError(t, err)
+	err = os.Remove(filepath.Join(filepath.Dir(path), "default.target.wants", "shutd.service"))
+	assert.NoError(t, err)
+
+	statuses, err := Check(o)
+	assert.NoError(t, err)
+	assert.False(t, statuses[1].Installed)
+}
+
+func TestInstallUnknownMethod(t *testing.T) {
+	o := tempHomeOptions(t)
+	_, err := Install("launchd", o)
+	assert.EqualError(t, err, "unknown autostart method: launchd")
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package autostart
+
+var installers = map[Method]func(o Options) installer{}
+
+// Methods supported on the platform, the first one is default
+func Methods() []Method {
+	return nil
+}
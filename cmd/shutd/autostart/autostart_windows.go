@@ -0,0 +1,93 @@
+//go:build windows
+
+package autostart
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	// Startup folder script, runs shutd without console window
+	Startup Method = "startup"
+	// Registry Run key of current user
+	Registry Method = "registry"
+)
+
+const (
+	runKeyPath   = `Software\Microsoft\Windows\CurrentVersion\Run`
+	runValueName = "shutd"
+)
+
+var installers = map[Method]func(o Options) installer{
+	Startup: func(o Options) installer {
+		return fileInstaller{method: Startup, path: filepath.Join(o.ConfigDir, "Microsoft", "Windows", "Start Menu", "Programs", "Startup", "shutd.vbs"), content: startupScript}
+	},
+	Registry: func(o Options) installer {
+		return registryInstaller{}
+	},
+}
+
+// Methods supported on the platform, the first one is default
+func Methods() []Method {
+	return []Method{Startup, Registry}
+}
+
+func startupScript(exe string) string {
+	return fmt.Sprintf("Set WshShell = CreateObject(\"WScript.Shell\")\r\nWshShell.Run Chr(34) & \"%v\" & Chr(34), 0\r\nSet WshShell = Nothing\r\n", exe)
+}
+
+type registryInstaller struct{}
+
+func (registryInstaller) install(exe string) (string, error) {
+	k, _, err := registry.CreateKey(registry.CURRENT_USER, runKeyPath, registry.SET_VALUE)
+	if err != nil {
+		return "", fmt.Errorf("failed to open registry run key: %w", err)
+	}
+	defer k.Close()
+	err = k.SetStringValue(runValueName, fmt.Sprintf(`"%v"`, exe))
+	if err != nil {
+		return "", fmt.Errorf("failed to set registry run value: %w", err)
+	}
+	return `HKCU\` + runKeyPath + `\` + runValueName, nil
+}
+
+func (registryInstaller) uninstall() error {
+	k, err := registry.OpenKey(registry.CURRENT_USER, runKeyPath, registry.SET_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to open registry run key: %w", err)
+	}
+	defer k.Close()
+	err = k.DeleteValue(runValueName)
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return fmt.Errorf("failed to delete registry run value: %w", err)
+	}
+	return nil
+}
+
+func (registryInstaller) status() (Status, error) {
+	status := Status{Method: Registry, Path: `HKCU\` + runKeyPath + `\` + runValueName}
+	k, err := registry.OpenKey(registry.CURRENT_USER, runKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return status, nil
+		}
+		return Status{}, fmt.Errorf("failed to open registry run key: %w", err)
+	}
+	defer k.Close()
+	_, _, err = k.GetStringValue(runValueName)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return status, nil
+		}
+		return Status{}, fmt.Errorf("failed to read registry run value: %w", err)
+	}
+	status.Installed = true
+	return status, nil
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/horacehylee/shutd"
+	"github.com/horacehylee/shutd/cmd/shutd/instance"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+// commands run by the invocation itself
+var commands = map[string]func(log *logrus.Logger, args []string) error{
+	"simulate":  runSimulate,
+	"install":   runInstall,
+	"uninstall": runUninstall,
+	"hash-pin":  runHashPIN,
+}
+
+// forwardedCommands are forwarded to and handled by the running instance
+var forwardedCommands = map[string]bool{
+	"status":   true,
+	"snooze":   true,
+	"skip":     true,
+	"pause":    true,
+	"resume":   true,
+	"shutdown": true,
+	"at":       true,
+	"in":       true,
+	"after":    true,
+	"profile":  true,
+}
+
+func runCommand(name string, args []string) {
+	log := newConsoleLogger()
+	var err error
+	if forwardedCommands[name] {
+		err = forwardCommand(append([]string{name}, args...))
+	} else if command, ok := commands[name]; ok {
+		err = command(log, args)
+	} else {
+		fmt.Fprintf(os.Stderr, "unknown command: %v\n", name)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func newConsoleLogger() *logrus.Logger {
+	log := logrus.New()
+	log.Out = os.Stderr
+	log.Level = logrus.WarnLevel
+	return log
+}
+
+func lockPath() string {
+	dirname, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("failed to get home dir: %w", err))
+		os.Exit(1)
+	}
+	return path.Join(dirname, ".shutd.lock")
+}
+
+// acquireLock to ensure single running instance, exits if another instance is running
+func acquireLock() *instance.Lock {
+	lock, err := instance.Acquire(lockPath())
+	if err != nil {
+		var e *instance.LockedError
+		if errors.As(err, &e) {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return lock
+}
+
+func forwardCommand(args []string) error {
+	lock, err := instance.Running(lockPath())
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return fmt.Errorf("shutd is not running")
+	}
+	output, err := lock.Forward(args)
+	// PIN is asked on terminal only once it is required, so it is not kept in shell history
+	if err != nil && errors.Is(err, shutd.ErrPINRequired) && term.IsTerminal(int(os.Stdin.Fd())) {
+		pin, err := readPIN("PIN: ")
+		if err != nil {
+			return err
+		}
+		output, err = lock.Forward(append(args, "--pin", pin))
+		if output != "" {
+			fmt.Println(output)
+		}
+		return err
+	}
+	if output != "" {
+		fmt.Println(output)
+	}
+	return err
+}
+
+// handleCommand handles commands forwarded from other invocations
+func handleCommand(s *shutd.Scheduler, profiles *profiles) instance.Handler {
+	return func(args []string) (string, error) {
+		args, pin := pinFlag(args)
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing command")
+		}
+		switch args[0] {
+		case "status":
+			return status(s, profiles)
+		case "snooze":
+			if len(args) > 1 {
+				return "", fmt.Errorf("usage: shutd snooze")
+			}
+			err := s.SnoozeWithPIN(pin)
+			if err != nil {
+				return "", err
+			}
+			return status(s, profiles)
+		case "skip":
+			if len(args) > 1 {
+				return "", fmt.Errorf("usage: shutd skip")
+			}
+			err := s.SkipWithPIN(pin)
+			if err != nil {
+				return "", err
+			}
+			return status(s, profiles)
+		case "pause":
+			if len(args) > 1 {
+				return "", fmt.Errorf("usage: shutd pause")
+			}
+			err := s.PauseWithPIN(pin)
+			if err != nil {
+				return "", err
+			}
+			return status(s, profiles)
+		case "resume":
+			err := s.Resume()
+			if err != nil {
+				return "", err
+			}
+			return status(s, profiles)
+		case "shutdown":
+			return "", s.ShutdownNow()
+		case "at", "in":
+			t, err := oneOffTime(args[0], args[1:], time.Now())
+			if err != nil {
+				return "", err
+			}
+			err = s.ShutdownAtWithPIN(t, pin)
+			if err != nil {
+				return "", err
+			}
+			return status(s, profiles)
+		case "profile":
+			if len(args) == 1 {
+				return profiles.list(), nil
+			}
+			err := s.VerifyPIN(pin)
+			if err != nil {
+				return "", err
+			}
+			err = profiles.switchTo(args[1])
+			if err != nil {
+				return "", err
+			}
+			return status(s, profiles)
+		case "after":
+			w, err := processWatch(args[1:])
+			if err != nil {
+				return "", err
+			}
+			err = s.ShutdownAfter(w)
+			if err != nil {
+				return "", err
+			}
+			return status(s, profiles)
+		}
+		return "", fmt.Errorf("unknown command: %v", args[0])
+	}
+}
+
+// oneOffTime parses args of "at 23:15" or "in 90m", bare number is treated as minutes
+func oneOffTime(command string, args []string, now time.Time) (time.Time, error) {
+	if len(args) != 1 {
+		return time.Time{}, fmt.Errorf("usage: shutd %v <time>", command)
+	}
+	if command == "in" {
+		if m, err := strconv.ParseFloat(args[0], 64); err == nil {
+			return now.Add(time.Duration(m * float64(time.Minute))).Truncate(time.Second), nil
+		}
+		d, err := time.ParseDuration(args[0])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid duration %q: %v", args[0], err)
+		}
+		return now.Add(d).Truncate(time.Second), nil
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		clock, err := time.Parse(layout, args[0])
+		if err != nil {
+			continue
+		}
+		t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, now.Location())
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: %w", args[0], shutd.ErrInvalidTime)
+}
+
+// processWatch parses args of "after --pid 1234" or "after --process-name rsync"
+func processWatch(args []string) (shutd.ProcessWatch, error) {
+	var w shutd.ProcessWatch
+	var usage strings.Builder
+	flags := flag.NewFlagSet("after", flag.ContinueOnError)
+	flags.SetOutput(&usage)
+	flags.IntVar(&w.PID, "pid", 0, "shut down after the process of pid exits")
+	flags.StringVar(&w.Name, "process-name", "", "shut down after all processes of the name exit")
+	err := flags.Parse(args)
+	if err != nil {
+		return w, fmt.Errorf("%v\n%v", err, strings.TrimSpace(usage.String()))
+	}
+	return w, nil
+}
+
+func status(s *shutd.Scheduler, profiles *profiles) (string, error) {
+	shutdownTime, err := s.ShutdownTime()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Next shutdown: %v (in %v)\n", shutdownTime.Format("Mon 2006-01-02 15:04"), time.Until(shutdownTime).Round(time.Minute))
+	if _, name := profiles.names(); name != "" {
+		fmt.Fprintf(&b, "Profile: %v\n", name)
+	}
+	if r := s.ShutdownRequest(); r.Source != shutd.SourceScheduled {
+		fmt.Fprintf(&b, "Reason: %v (%v)\n", r.Reason, r.Source)
+	}
+	if w, ok := s.WatchedProcess(); ok {
+		fmt.Fprintf(&b, "Waiting for: %v to exit\n", w)
+	}
+	if wakeTime, ok := s.WakeTime(); ok {
+		fmt.Fprintf(&b, "Wake at: %v\n", wakeTime.Format("Mon 2006-01-02 15:04"))
+	}
+	fmt.Fprintf(&b, "Snoozed: %v times\n", s.SnoozeCount())
+	fmt.Fprintf(&b, "Skipped: %v\n", s.Skipped())
+	if reason, ok := s.SkipReason(shutdownTime); ok {
+		fmt.Fprintf(&b, "Skipped by: %v\n", reason)
+	}
+	fmt.Fprintf(&b, "Paused: %v\n", s.Paused())
+	fmt.Fprintf(&b, "Dry run: %v", s.Config().DryRun)
+	if s.Protected() {
+		fmt.Fprintf(&b, "\nProtected: PIN is required to snooze, skip, pause, switch profile and shut down later")
+	}
+	for _, lock := range policyLocks(s.Config().Policy) {
+		fmt.Fprintf(&b, "\nLocked: %v", lock)
+	}
+	for _, e := range s.History() {
+		fmt.Fprintf(&b, "\nHistory: %v", historyLine(e))
+	}
+	return b.String(), nil
+}
+
+// historyLine describes started or failed shutdown with its reason
+func historyLine(e shutd.Event) string {
+	line := fmt.Sprintf("%v %v by %v", e.Time.Format("Mon 2006-01-02 15:04"), strings.TrimPrefix(string(e.Type), "shutdown_"), e.Source)
+	if e.Reason != "" {
+		line += fmt.Sprintf(" (%v)", e.Reason)
+	}
+	if e.DryRun {
+		line += ", dry run"
+	}
+	if e.Err != nil {
+		line += fmt.Sprintf(": %v", e.Err)
+	}
+	return line
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/horacehylee/shutd/cmd/shutd/autostart"
+	"github.com/sirupsen/logrus"
+)
+
+func runInstall(log *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet("install", flag.ExitOnError)
+	method := flags.String("method", "", fmt.Sprintf("autostart method, one of %v (default %v)", autostart.Methods(), defaultMethod()))
+	check := flags.Bool("check", false, "report whether autostart is configured")
+	flags.Parse(args)
+
+	o, err := autostart.DefaultOptions()
+	if err != nil {
+		return err
+	}
+	if *check {
+		return printAutostartStatus(o)
+	}
+	path, err := autostart.Install(autostart.Method(*method), o)
+	if err != nil {
+		return fmt.Errorf("failed to install autostart: %w", err)
+	}
+	fmt.Printf("Installed autostart: %v\n", path)
+	return nil
+}
+
+func runUninstall(log *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet("uninstall", flag.ExitOnError)
+	method := flags.String("method", "", "autostart method to uninstall (default all)")
+	flags.Parse(args)
+
+	o, err := autostart.DefaultOptions()
+	if err != nil {
+		return err
+	}
+	err = autostart.Uninstall(autostart.Method(*method), o)
+	if err != nil {
+		return fmt.Errorf("failed to uninstall autostart: %w", err)
+	}
+	fmt.Println("Uninstalled autostart")
+	return nil
+}
+
+func printAutostartStatus(o autostart.Options) error {
+	statuses, err := autostart.Check(o)
+	if err != nil {
+		return fmt.Errorf("failed to check autostart: %w", err)
+	}
+	configured := false
+	for _, s := range statuses {
+		state := "not installed"
+		if s.Installed {
+			state = "installed"
+			configured = true
+		}
+		fmt.Printf("%v: %v (%v)\n", s.Method, state, s.Path)
+	}
+	if !configured {
+		return fmt.Errorf("autostart is not configured")
+	}
+	return nil
+}
+
+func defaultMethod() autostart.Method {
+	methods := autostart.Methods()
+	if len(methods) == 0 {
+		return ""
+	}
+	return methods[0]
+}
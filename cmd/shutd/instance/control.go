@@ -0,0 +1,179 @@
+package instance
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/horacehylee/shutd"
+)
+
+const tokenHeader = "X-Shutd-Token"
+
+// Handler of forwarded command arguments, returns output to be printed by the forwarding invocation
+type Handler func(args []string) (string, error)
+
+type commandRequest struct {
+	Args []string `json:"args"`
+}
+
+type commandResponse struct {
+	Output string `json:"output"`
+	Error  string `json:"error,omitempty"`
+	// Code of sentinel error wrapped by Error, so it could be matched by errors.Is after forwarding
+	Code string `json:"code,omitempty"`
+}
+
+// errorCodes of sentinel errors sent over control API, codes are stable while messages could change
+var errorCodes = []struct {
+	code string
+	err  error
+}{
+	{"closed", shutd.ErrClosed},
+	{"not_scheduled", shutd.ErrNotScheduled},
+	{"invalid_time", shutd.ErrInvalidTime},
+	{"snooze_limit_reached", shutd.ErrSnoozeLimitReached},
+	{"not_snoozable", shutd.ErrNotSnoozable},
+	{"not_allowed", shutd.ErrNotAllowed},
+	{"pin_required", shutd.ErrPINRequired},
+	{"wrong_pin", shutd.ErrWrongPIN},
+	{"pin_locked", shutd.ErrPINLocked},
+}
+
+func errorCode(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return ""
+}
+
+// ForwardedError returned by the running instance, it unwraps to the sentinel error of its code
+type ForwardedError struct {
+	Message string
+	Code    string
+}
+
+func (e *ForwardedError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the sentinel error of the code, nil for unknown code
+func (e *ForwardedError) Unwrap() error {
+	for _, c := range errorCodes {
+		if c.code == e.Code {
+			return c.err
+		}
+	}
+	return nil
+}
+
+// Client of control server of running instance
+type Client struct {
+	Addr  string
+	Token string
+}
+
+// Serve forwarded commands on loopback address, the address is recorded into lock file
+func (l *Lock) Serve(handler Handler) error {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to listen control server: %w", err)
+	}
+	err = l.SetAddr(ln.Addr().String())
+	if err != nil {
+		ln.Close()
+		return fmt.Errorf("failed to record control server address: %w", err)
+	}
+	go http.Serve(ln, controlHandler(l.Token, handler))
+	return nil
+}
+
+// ServeAddr serves commands on addr, e.g. for peers on the network, token is required
+func ServeAddr(addr, token string, handler Handler) (io.Closer, error) {
+	if token == "" {
+		return nil, fmt.Errorf("token is required to serve commands on %v", addr)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen control server: %w", err)
+	}
+	go http.Serve(ln, controlHandler(token, handler))
+	return ln, nil
+}
+
+func controlHandler(token string, handler Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/command", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(tokenHeader)), []byte(token)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req commandRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var res commandResponse
+		res.Output, err = handler(req.Args)
+		if err != nil {
+			res.Error = err.Error()
+			res.Code = errorCode(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	})
+	return mux
+}
+
+// Forward command arguments to the running instance holding the lock
+func (l *Lock) Forward(args []string) (string, error) {
+	if l.Addr == "" {
+		return "", fmt.Errorf("running instance is not accepting commands")
+	}
+	return Client{Addr: l.Addr, Token: l.Token}.Forward(args)
+}
+
+// Forward command arguments to the instance
+func (c Client) Forward(args []string) (string, error) {
+	b, err := json.Marshal(commandRequest{Args: args})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, "http://"+c.Addr+"/command", bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(tokenHeader, c.Token)
+	req.Header.Set("Content-Type", "application/json")
+	client := http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to forward command: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to forward command: %v", resp.Status)
+	}
+	var res commandResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse command response: %w", err)
+	}
+	if res.Error != "" {
+		return res.Output, &ForwardedError{Message: res.Error, Code: res.Code}
+	}
+	return res.Output, nil
+}
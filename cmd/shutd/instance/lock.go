@@ -0,0 +1,158 @@
+// Package instance ensures only single shutd daemon is running per user,
+// and forwards commands from later invocations to the running daemon
+package instance
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// readLockTimeout for the lock file to be written by the instance holding the lock
+const readLockTimeout = time.Second
+
+// Lock held by the running instance
+type Lock struct {
+	PID   int    `json:"pid"`
+	Addr  string `json:"addr"`
+	Token string `json:"token"`
+
+	path string
+	// guard file locked by OS for as long as the instance is running, it is never removed
+	guard *os.File
+}
+
+// LockedError when another instance is holding the lock
+type LockedError struct {
+	Lock *Lock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("shutd is already running (pid %v)", e.Lock.PID)
+}
+
+// Acquire lock at path for current process. Exclusion is by OS lock of a guard file next to it,
+// so lock file left by dead process is taken over, while lock file being written by another instance is not
+func Acquire(path string) (*Lock, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	guard, err := os.OpenFile(path+".guard", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock guard file: %w", err)
+	}
+	ok, err := tryLock(guard)
+	if err != nil || !ok {
+		guard.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock guard file: %w", err)
+		}
+		existing, err := readWritten(path)
+		if err != nil {
+			return nil, fmt.Errorf("shutd is already running: %w", err)
+		}
+		return nil, &LockedError{Lock: existing}
+	}
+	lock := &Lock{PID: os.Getpid(), Token: token, path: path, guard: guard}
+	err = lock.write()
+	if err != nil {
+		guard.Close()
+		return nil, fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return lock, nil
+}
+
+// readWritten lock file at path, waits for the instance holding the lock to write it
+func readWritten(path string) (*Lock, error) {
+	deadline := time.Now().Add(readLockTimeout)
+	for {
+		lock, err := Read(path)
+		if err == nil || time.Now().After(deadline) {
+			return lock, err
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Read lock file at path
+func Read(path string) (*Lock, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lock Lock
+	err = json.Unmarshal(b, &lock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse lock file: %w", err)
+	}
+	lock.path = path
+	return &lock, nil
+}
+
+// Running returns lock of the running instance, nil if there is no running instance
+func Running(path string) (*Lock, error) {
+	lock, err := Read(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !processAlive(lock.PID) {
+		return nil, nil
+	}
+	return lock, nil
+}
+
+// SetAddr of control server and persist it into lock file
+func (l *Lock) SetAddr(addr string) error {
+	l.Addr = addr
+	return l.write()
+}
+
+// Release the lock, lock file is removed before the guard file is unlocked
+func (l *Lock) Release() error {
+	err := os.Remove(l.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		err = fmt.Errorf("failed to remove lock file: %w", err)
+	} else {
+		err = nil
+	}
+	// closing the guard file releases its OS lock
+	if err1 := l.guard.Close(); err == nil && err1 != nil {
+		err = fmt.Errorf("failed to unlock guard file: %w", err1)
+	}
+	return err
+}
+
+// write lock file to a temporary file and rename it into place, so it is never read partially written
+func (l *Lock) write() error {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	err = os.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp, l.path)
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,147 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/horacehylee/shutd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireAndRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".shutd.lock")
+	lock, err := Acquire(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.Getpid(), lock.PID)
+
+	running, err := Running(path)
+	assert.NoError(t, err)
+	assert.Equal(t, lock.Token, running.Token)
+
+	err = lock.Release()
+	assert.NoError(t, err)
+
+	running, err = Running(path)
+	assert.NoError(t, err)
+	assert.Nil(t, running)
+}
+
+func TestAcquireWhenLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".shutd.lock")
+	lock, err := Acquire(path)
+	assert.NoError(t, err)
+	defer lock.Release()
+
+	_, err = Acquire(path)
+	var e *LockedError
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, os.Getpid(), e.Lock.PID)
+	assert.EqualError(t, err, fmt.Sprintf("shutd is already running (pid %v)", os.Getpid()))
+}
+
+func TestAcquireStaleLock(t *testing.T) {
+	cmd := exec.Command("go", "version")
+	assert.NoError(t, cmd.Run())
+
+	path := filepath.Join(t.TempDir(), ".shutd.lock")
+	stale := &Lock{PID: cmd.Process.Pid, path: path}
+	assert.NoError(t, stale.write())
+
+	lock, err := Acquire(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.Getpid(), lock.PID)
+}
+
+func TestAcquireCorruptedLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".shutd.lock")
+	assert.NoError(t, os.WriteFile(path, []byte("garbage"), 0600))
+
+	lock, err := Acquire(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.Getpid(), lock.PID)
+}
+
+func TestAcquireDoesNotTakeOverUnwrittenLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".shutd.lock")
+	lock, err := Acquire(path)
+	assert.NoError(t, err)
+	defer lock.Release()
+	// another instance has just locked but not written its lock file yet
+	assert.NoError(t, os.WriteFile(path, nil, 0600))
+
+	_, err = Acquire(path)
+	assert.Error(t, err)
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Empty(t, b, "lock file should not be taken over")
+}
+
+func TestAcquireAfterRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".shutd.lock")
+	lock, err := Acquire(path)
+	assert.NoError(t, err)
+	assert.NoError(t, lock.Release())
+
+	lock, err = Acquire(path)
+	assert.NoError(t, err)
+	assert.NoError(t, lock.Release())
+}
+
+func TestForward(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".shutd.lock")
+	lock, err := Acquire(path)
+	assert.NoError(t, err)
+	defer lock.Release()
+
+	err = lock.Serve(func(args []string) (string, error) {
+		if args[0] == "fail" {
+			return "", fmt.Errorf("failed")
+		}
+		if args[0] == "snooze" {
+			return "", fmt.Errorf("snooze past 22:30: %w", shutd.ErrNotAllowed)
+		}
+		return fmt.Sprintf("received %v", args), nil
+	})
+	assert.NoError(t, err)
+
+	running, err := Running(path)
+	assert.NoError(t, err)
+	output, err := running.Forward([]string{"status"})
+	assert.NoError(t, err)
+	assert.Equal(t, "received [status]", output)
+
+	_, err = running.Forward([]string{"fail"})
+	assert.EqualError(t, err, "failed")
+
+	_, err = running.Forward([]string{"snooze"})
+	assert.EqualError(t, err, "snooze past 22:30: not allowed by policy")
+	assert.ErrorIs(t, err, shutd.ErrNotAllowed)
+
+	running.Token = "wrong"
+	_, err = running.Forward([]string{"status"})
+	assert.EqualError(t, err, "failed to forward command: 401 Unauthorized")
+}
+
+func TestServeAddr(t *testing.T) {
+	_, err := ServeAddr("127.0.0.1:0", "", nil)
+	assert.EqualError(t, err, "token is required to serve commands on 127.0.0.1:0")
+
+	ln, err := ServeAddr("127.0.0.1:0", "secret", func(args []string) (string, error) {
+		return fmt.Sprintf("received %v", args), nil
+	})
+	assert.NoError(t, err)
+	defer ln.Close()
+	addr := ln.(net.Listener).Addr().String()
+
+	output, err := Client{Addr: addr, Token: "secret"}.Forward([]string{"shutdown"})
+	assert.NoError(t, err)
+	assert.Equal(t, "received [shutdown]", output)
+
+	_, err = Client{Addr: addr, Token: "wrong"}.Forward([]string{"shutdown"})
+	assert.EqualError(t, err, "failed to forward command: 401 Unauthorized")
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package instance
+
+import (
+	"errors"
+	"syscall"
+)
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	// EPERM means the process exists but owned by another user
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
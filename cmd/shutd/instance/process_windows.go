@@ -0,0 +1,21 @@
+//go:build windows
+
+package instance
+
+import "golang.org/x/sys/windows"
+
+const stillActive = 259
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(h)
+	var code uint32
+	err = windows.GetExitCodeProcess(h, &code)
+	return err == nil && code == stillActive
+}
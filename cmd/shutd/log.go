@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+type logConfig struct {
+	Format     string
+	Level      string
+	MaxSizeMB  int
+	MaxBackups int
+	Path       string
+}
+
+// rotatingLogger writes to size rotated log file, and could be reconfigured at runtime
+type rotatingLogger struct {
+	*logrus.Logger
+	file   *lumberjack.Logger
+	stderr bool
+}
+
+func newLogger(stderr bool) *rotatingLogger {
+	log := logrus.New()
+	l := &rotatingLogger{Logger: log, stderr: stderr}
+	l.configure(logConfig{Format: "text", Level: "info", MaxSizeMB: 10, MaxBackups: 3})
+	return l
+}
+
+// configure format, level and rotation of the logger, invalid values are reported and ignored
+func (l *rotatingLogger) configure(config logConfig) {
+	switch config.Format {
+	case "json":
+		l.SetFormatter(&logrus.JSONFormatter{})
+	case "text", "":
+		l.SetFormatter(&logrus.TextFormatter{DisableColors: true, FullTimestamp: true})
+	default:
+		l.Errorf("unknown log format: %v", config.Format)
+	}
+
+	if config.Level != "" {
+		level, err := logrus.ParseLevel(config.Level)
+		if err != nil {
+			l.Errorf("invalid log level: %v", err)
+		} else {
+			l.SetLevel(level)
+		}
+	}
+
+	filename := config.Path
+	if filename == "" {
+		dirname, err := os.UserHomeDir()
+		if err != nil {
+			l.Fatal(fmt.Errorf("failed to get home dir: %w", err))
+		}
+		filename = path.Join(dirname, ".shutd.log")
+	}
+	if l.file != nil && l.file.Filename == filename && l.file.MaxSize == config.MaxSizeMB && l.file.MaxBackups == config.MaxBackups {
+		return
+	}
+	old := l.file
+	l.file = &lumberjack.Logger{
+		Filename:   filename,
+		MaxSize:    config.MaxSizeMB,
+		MaxBackups: config.MaxBackups,
+	}
+	var out io.Writer = l.file
+	if l.stderr {
+		out = io.MultiWriter(l.file, os.Stderr)
+	}
+	l.SetOutput(out)
+	if old != nil {
+		old.Close()
+	}
+}
+
+// Close the log file
+func (l *rotatingLogger) Close() error {
+	return l.file.Close()
+}
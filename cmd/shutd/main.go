@@ -1,165 +1,302 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
-	"path"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
-	"github.com/getlantern/systray"
 	"github.com/horacehylee/shutd"
-	"github.com/horacehylee/shutd/cmd/shutd/icon"
+	"github.com/horacehylee/shutd/cmd/shutd/instance"
+	"github.com/horacehylee/shutd/cmd/shutd/peer"
+	"github.com/horacehylee/shutd/cmd/shutd/profile"
+	"github.com/horacehylee/shutd/cmd/shutd/systemd"
+	"github.com/mitchellh/mapstructure"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
+// exitTimeout for running tasks to finish on exit
+const exitTimeout = 5 * time.Second
+
+// configMu guards the user config parsed from viper and serializes applying it, as it is applied by the file watcher
+// and again on policy change. Viper is only read on start and by its watcher, as it is not safe for concurrent use
+var configMu sync.Mutex
+
+type daemonFlags struct {
+	dryRun   bool
+	headless bool
+}
+
 func main() {
-	logFile, log := newLogger()
-	defer logFile.Close()
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+	flags := parseDaemonFlags(os.Args[1:])
+
+	lock := acquireLock()
+
+	// journald or terminal picks up stderr in headless mode
+	logs := newLogger(flags.headless)
+	defer logs.Close()
+	log := logs.Logger
 
-	log.Info("==========================")
 	log.Info("Started")
-	log.Info("==========================")
 
-	config := newConfig(log)
+	policy := newPolicy(log)
+	user := newConfig(log)
+	config := policy.apply(flags.apply(user.config))
+	logs.configure(user.log)
 	s, err := shutd.NewScheduler(config, shutd.WithLogger(log))
 	if err != nil {
-		log.Fatalf("failed create scheduler: %w", err)
+		log.Fatalf("failed create scheduler: %v", err)
 	}
 
-	watchConfig(log, func(config shutd.Config) {
-		err := s.Configure(config)
+	mqtt := &mqttBridge{log: log, s: s}
+	mqtt.configure(config.MQTT)
+
+	profiles := &profiles{log: log, current: user}
+	peers := &peers{log: log, s: s, profiles: profiles}
+	peers.configure(user.peers, user.control)
+	profiles.configure = func(user userConfig) {
+		logs.configure(user.log)
+		err := s.Configure(policy.apply(flags.apply(user.config)))
 		if err != nil {
-			log.Fatalf("failed to apply updated config: %w", err)
+			log.Fatalf("failed to apply updated config: %v", err)
 		}
+		mqtt.configure(user.config.MQTT)
+		peers.configure(user.peers, user.control)
+	}
+	watchConfig(log, profiles.apply)
+	profiles.file = viper.ConfigFileUsed()
+	policy.watch(func() {
+		configMu.Lock()
+		defer configMu.Unlock()
+		profiles.apply(profiles.current)
 	})
 
-	watchExit(log)
+	err = lock.Serve(handleCommand(s, profiles))
+	if err != nil {
+		log.Errorf("failed to serve commands: %v", err)
+	}
+
+	watchExit(log, s, lock)
 
-	startSystray(log, s)
+	if flags.headless {
+		runHeadless(log, s)
+		return
+	}
+	startSystray(log, s, lock, profiles, policy)
 }
 
-func newLogger() (*os.File, *logrus.Logger) {
-	log := logrus.New()
-	log.Formatter = new(logrus.TextFormatter)
-	log.Formatter.(*logrus.TextFormatter).DisableColors = true
-	log.Formatter.(*logrus.TextFormatter).FullTimestamp = true
-	log.Level = logrus.InfoLevel
+func parseDaemonFlags(args []string) daemonFlags {
+	var flags daemonFlags
+	f := flag.NewFlagSet("shutd", flag.ExitOnError)
+	f.BoolVar(&flags.dryRun, "dry-run", false, "run notification and snooze flow without powering off")
+	f.BoolVar(&flags.headless, "headless", false, "run without systray, notifications default to wall")
+	f.Parse(args)
+	return flags
+}
 
-	dirname, err := os.UserHomeDir()
-	if err != nil {
-		log.Fatal(fmt.Errorf("failed to get home dir: %w", err))
+// apply command line flags on top of config
+func (f daemonFlags) apply(config shutd.Config) shutd.Config {
+	if f.dryRun {
+		config.DryRun = true
+	}
+	if f.headless && (config.Notification.Notifier == "" || config.Notification.Notifier == shutd.DialogNotifier) {
+		config.Notification.Notifier = shutd.WallNotifier
 	}
-	file, err := os.OpenFile(path.Join(dirname, ".shutd.log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0660)
+	return config
+}
+
+// userConfig parsed from config file with settings of the active profile on top
+type userConfig struct {
+	config  shutd.Config
+	log     logConfig
+	peers   []peer.Peer
+	control controlConfig
+	// profiles names and the active one
+	profiles []string
+	active   string
+}
+
+func newConfig(log *logrus.Logger) userConfig {
+	config := readConfig(log)
+
+	err := viper.SafeWriteConfig()
 	if err != nil {
-		log.Fatal(fmt.Errorf("failed to log to file: %w", err))
+		var e viper.ConfigFileAlreadyExistsError
+		if !errors.As(err, &e) {
+			log.Fatal(fmt.Errorf("failed to write config: %w", err))
+		}
 	}
-	log.Out = file
-	return file, log
+	return config
 }
 
-func newConfig(log *logrus.Logger) shutd.Config {
+func readConfig(log *logrus.Logger) userConfig {
 	viper.SetConfigName(".shutd")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath("$HOME")
 
 	viper.SetDefault("startTime", "01:00")
-	viper.SetDefault("snoozeInterval", 15)
-	viper.SetDefault("notification.before", 10)
-	viper.SetDefault("notification.duration", 10)
+	viper.SetDefault("snoozeInterval", "15m")
+	viper.SetDefault("maxSnoozes", 0)
+	viper.SetDefault("notification.before", "10m")
+	viper.SetDefault("notification.duration", "10m")
+	viper.SetDefault("notification.notifier", shutd.DialogNotifier)
+	viper.SetDefault("wakeTime", "")
+	viper.SetDefault("dryRun", false)
+	viper.SetDefault("battery.shutdownBelow", 0)
+	viper.SetDefault("battery.skipWhileCharging", false)
+	viper.SetDefault("battery.pollInterval", "1m")
+	viper.SetDefault("ups.host", "")
+	viper.SetDefault("ups.port", 3493)
+	viper.SetDefault("ups.name", "ups")
+	viper.SetDefault("ups.shutdownAfter", "0s")
+	viper.SetDefault("ups.notice", "1m")
+	viper.SetDefault("ups.pollInterval", "10s")
+	viper.SetDefault("thermal.shutdownAbove", 0)
+	viper.SetDefault("thermal.for", "1m")
+	viper.SetDefault("thermal.notice", "1m")
+	viper.SetDefault("thermal.pollInterval", "10s")
+	viper.SetDefault("quiet.cpuBelow", 0)
+	viper.SetDefault("quiet.netBelowKBps", 0)
+	viper.SetDefault("quiet.for", "10m")
+	viper.SetDefault("quiet.pollInterval", "10s")
+	viper.SetDefault("process.notice", "1m")
+	viper.SetDefault("process.pollInterval", "5s")
+	viper.SetDefault("skipDates", []string{})
+	viper.SetDefault("calendar.ics", "")
+	viper.SetDefault("calendar.skipOnEventsMatching", "")
+	viper.SetDefault("protect.pinHash", "")
+	viper.SetDefault("protect.maxAttempts", 5)
+	viper.SetDefault("protect.lockout", "5m")
+	viper.SetDefault("log.format", "text")
+	viper.SetDefault("log.level", "info")
+	viper.SetDefault("log.maxSizeMB", 10)
+	viper.SetDefault("log.maxBackups", 3)
+	viper.SetDefault("log.path", "")
 
 	err := viper.ReadInConfig()
 	if err != nil {
-		var e *viper.ConfigFileNotFoundError
+		var e viper.ConfigFileNotFoundError
 		if !errors.As(err, &e) {
 			log.Fatal(fmt.Errorf("could not read config: %w", err))
 		}
 	}
-	config := parseConfig(log)
-
-	err = viper.SafeWriteConfig()
-	if err != nil {
-		var e viper.ConfigFileAlreadyExistsError
-		if !errors.As(err, &e) {
-			log.Fatal(fmt.Errorf("failed to write config: %w", err))
-		}
-	}
-	return config
+	return parseConfig(log)
 }
 
-func watchConfig(log *logrus.Logger, configFunc func(config shutd.Config)) {
+// watchConfig file, configFunc is called with configMu held. It returns once the file is watched
+func watchConfig(log *logrus.Logger, configFunc func(config userConfig)) {
 	viper.OnConfigChange(func(e fsnotify.Event) {
-		log.Info("==========================")
-		log.Info("Config file changed:", e.Name)
-		log.Info("==========================")
+		// viper is read by its watcher only
 		config := parseConfig(log)
+		configMu.Lock()
+		defer configMu.Unlock()
+		log.WithField("file", e.Name).Info("Config file changed")
 		configFunc(config)
 	})
 	viper.WatchConfig()
 }
 
-func parseConfig(log *logrus.Logger) shutd.Config {
-	var config shutd.Config
-	err := viper.Unmarshal(&config)
+// parseConfig with settings of the active profile on top, e.g. log and peers could be set by profile
+func parseConfig(log *logrus.Logger) userConfig {
+	settings := viper.AllSettings()
+	user := userConfig{profiles: profileNames(), active: activeProfile()}
+	if user.active != "" {
+		p, ok := profileSettings(user.active)
+		if ok {
+			settings = profile.Merge(settings, p)
+		} else {
+			log.Errorf("unknown active profile %q, available profiles: %v", user.active, strings.Join(user.profiles, ", "))
+		}
+	}
+	err := decodeSettings(settings, &user.config)
 	if err != nil {
 		log.Fatal(fmt.Errorf("failed to parse config: %w", err))
 	}
-	return config
+	err = decodeSettings(settings["log"], &user.log)
+	if err != nil {
+		log.Fatal(fmt.Errorf("failed to parse log config: %w", err))
+	}
+	err = decodeSettings(settings["peers"], &user.peers)
+	if err != nil {
+		log.WithError(err).Error("failed to parse peers config")
+	}
+	err = decodeSettings(settings["control"], &user.control)
+	if err != nil {
+		log.WithError(err).Error("failed to parse control config")
+	}
+	return user
+}
+
+func decodeSettings(settings interface{}, result interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       shutd.ConfigDecodeHook(),
+		Result:           result,
+		WeaklyTypedInput: true,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(settings)
 }
 
-func exit(log *logrus.Logger) {
-	log.Info("==========================")
+func exit(log *logrus.Logger, s *shutd.Scheduler, lock *instance.Lock) {
+	_, err := systemd.Notify(systemd.Stopping)
+	if err != nil {
+		log.Errorf("failed to notify systemd: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), exitTimeout)
+	defer cancel()
+	err = s.Close(ctx)
+	if err != nil {
+		log.Errorf("failed to close scheduler: %v", err)
+	}
+	err = lock.Release()
+	if err != nil {
+		log.Errorf("failed to release lock: %v", err)
+	}
 	log.Info("Exited")
-	log.Info("==========================")
 }
 
-func watchExit(log *logrus.Logger) {
+// runHeadless blocks with scheduler running, exit is handled by watchExit
+func runHeadless(log *logrus.Logger, s *shutd.Scheduler) {
+	ok, err := systemd.Notify(systemd.Ready)
+	if err != nil {
+		log.Errorf("failed to notify systemd: %v", err)
+	}
+	if ok {
+		log.Info("notified systemd for readiness")
+	}
+	// scheduler is healthy once it responds, rather than stuck on its lock
+	healthy := func() error {
+		s.ShutdownRequest()
+		return nil
+	}
+	if systemd.StartWatchdog(make(chan struct{}), healthy, func(err error) {
+		log.Errorf("failed to ping systemd watchdog: %v", err)
+	}) {
+		log.Info("systemd watchdog started")
+	}
+	select {}
+}
+
+func watchExit(log *logrus.Logger, s *shutd.Scheduler, lock *instance.Lock) {
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-sigs
-		exit(log)
+		exit(log, s, lock)
 		os.Exit(0)
 	}()
 }
-
-func startSystray(log *logrus.Logger, s *shutd.Scheduler) {
-	onReady := func() {
-		systray.SetTemplateIcon(icon.Data, icon.Data)
-		systray.SetTitle("Shutd")
-		systray.SetTooltip("Shutd")
-		shutdownTimeItem := systray.AddMenuItem("Shutdown at ?", "Shutdown at ?")
-		systray.AddSeparator()
-		snoozeItem := systray.AddMenuItem("Snooze", "Snooze shutdown")
-		quitItem := systray.AddMenuItem("Quit", "Quit the whole app")
-
-		shutdownTimeItem.Disable()
-
-		go func() {
-			for {
-				select {
-				case t := <-s.ShutdownTimeChangedChan():
-					title := fmt.Sprintf("Shutdown at %v", t.Format("15:04"))
-					shutdownTimeItem.SetTitle(title)
-					shutdownTimeItem.SetTooltip(title)
-				case <-snoozeItem.ClickedCh:
-					err := s.Snooze()
-					if err != nil {
-						log.Errorf("failed to snooze: %v", err)
-					}
-				case <-quitItem.ClickedCh:
-					systray.Quit()
-					return
-				}
-			}
-		}()
-	}
-
-	onExit := func() {
-		exit(log)
-	}
-	systray.Run(onReady, onExit)
-}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/horacehylee/shutd"
+	"github.com/sirupsen/logrus"
+)
+
+// mqttBridge reconnects MQTT bridge when its config is changed
+type mqttBridge struct {
+	log    *logrus.Logger
+	s      *shutd.Scheduler
+	config shutd.MQTTConfig
+	bridge *shutd.MQTTBridge
+}
+
+func (m *mqttBridge) configure(config shutd.MQTTConfig) {
+	if m.bridge != nil && m.config == config {
+		return
+	}
+	if m.bridge != nil {
+		m.bridge.Close()
+		m.bridge = nil
+	}
+	m.config = config
+	if config.Broker == "" {
+		return
+	}
+	bridge, err := shutd.NewMQTTBridge(m.s, config)
+	if err != nil {
+		m.log.WithError(err).Error("failed to start MQTT bridge")
+		return
+	}
+	m.bridge = bridge
+}
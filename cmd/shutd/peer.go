@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/horacehylee/shutd"
+	"github.com/horacehylee/shutd/cmd/shutd/instance"
+	"github.com/horacehylee/shutd/cmd/shutd/peer"
+	"github.com/sirupsen/logrus"
+)
+
+// controlConfig for serving commands to peers on the network
+type controlConfig struct {
+	// Listen address, e.g. 0.0.0.0:7878, empty to disable
+	Listen string
+	Token  string
+}
+
+// peers restarts peer coordinator and control server when their config is changed
+type peers struct {
+	log         *logrus.Logger
+	s           *shutd.Scheduler
+	profiles    *profiles
+	config      []peer.Peer
+	coordinator *peer.Coordinator
+	control     controlConfig
+	listener    io.Closer
+}
+
+func (p *peers) configure(config []peer.Peer, control controlConfig) {
+	p.configurePeers(config)
+	p.configureControl(control)
+}
+
+func (p *peers) configurePeers(config []peer.Peer) {
+	if p.coordinator != nil && reflect.DeepEqual(p.config, config) {
+		return
+	}
+	if p.coordinator != nil {
+		p.coordinator.Close()
+		p.coordinator = nil
+	}
+	p.config = config
+	if len(config) == 0 {
+		return
+	}
+	coordinator, err := peer.NewCoordinator(p.log, p.s, config)
+	if err != nil {
+		p.log.WithError(err).Error("failed to start peer coordinator")
+		return
+	}
+	p.coordinator = coordinator
+}
+
+func (p *peers) configureControl(control controlConfig) {
+	if p.listener != nil && p.control == control {
+		return
+	}
+	if p.listener != nil {
+		p.listener.Close()
+		p.listener = nil
+	}
+	p.control = control
+	if control.Listen == "" {
+		return
+	}
+	listener, err := instance.ServeAddr(control.Listen, control.Token, p.handleCommand)
+	if err != nil {
+		p.log.WithError(err).Error("failed to serve commands to peers")
+		return
+	}
+	p.log.WithField("addr", control.Listen).Info("serving commands to peers")
+	p.listener = listener
+}
+
+// handleCommand from peers on the network, only status and shutdown are allowed, so peers could not change the schedule.
+// Shutdown is replied before shutting down, as pre-shutdown hooks and webhooks could take longer than the timeout of peers
+func (p *peers) handleCommand(args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("missing command")
+	}
+	switch args[0] {
+	case "status":
+		return status(p.s, p.profiles)
+	case "shutdown":
+		go func() {
+			err := p.s.ShutdownForPeer()
+			if err != nil {
+				p.log.WithError(err).Error("failed to shut down as requested by peer")
+			}
+		}()
+		return "shutdown requested", nil
+	}
+	return "", fmt.Errorf("command is not allowed from peers: %v", args[0])
+}
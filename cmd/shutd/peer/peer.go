@@ -0,0 +1,160 @@
+// Package peer coordinates other machines to follow the shutdown schedule,
+// peers running shutd are asked to shut down and all peers are woken up with Wake-on-LAN
+package peer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/horacehylee/shutd"
+	"github.com/horacehylee/shutd/cmd/shutd/instance"
+	"github.com/sirupsen/logrus"
+)
+
+// Peer machine coordinated by this instance
+type Peer struct {
+	Name string
+	// MAC address for Wake-on-LAN, empty to not wake up the peer
+	MAC string
+	// Broadcast address of magic packet, defaults to 255.255.255.255:9
+	Broadcast string
+	// Addr of control server of shutd running on the peer, empty to not shut down the peer
+	Addr string
+	// Token of control server of the peer
+	Token string
+	// WakeTime to wake up the peer daily, e.g. "07:30" or "07:30:00", empty to not wake up the peer
+	WakeTime string
+}
+
+// String returns name of the peer, falls back to its address
+func (p Peer) String() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	if p.Addr != "" {
+		return p.Addr
+	}
+	return p.MAC
+}
+
+// Shutdown asks shutd running on the peer to shut down
+func (p Peer) Shutdown() error {
+	if p.Addr == "" {
+		return fmt.Errorf("peer %v has no control address", p)
+	}
+	_, err := instance.Client{Addr: p.Addr, Token: p.Token}.Forward([]string{"shutdown"})
+	return err
+}
+
+// Wake sends Wake-on-LAN magic packet to the peer
+func (p Peer) Wake() error {
+	if p.MAC == "" {
+		return fmt.Errorf("peer %v has no MAC address", p)
+	}
+	return SendMagicPacket(p.MAC, p.Broadcast)
+}
+
+// Coordinator shuts down peers along with the scheduler and wakes them up at their wake time
+type Coordinator struct {
+	log        *logrus.Logger
+	s          *shutd.Scheduler
+	peers      []Peer
+	removeHook func()
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+// NewCoordinator starts coordinating peers with the scheduler
+func NewCoordinator(log *logrus.Logger, s *shutd.Scheduler, peers []Peer) (*Coordinator, error) {
+	for _, p := range peers {
+		if p.WakeTime == "" {
+			continue
+		}
+		_, err := shutd.NextClockTime(time.Now(), p.WakeTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid wake time of peer %v: %w", p, err)
+		}
+	}
+	c := &Coordinator{
+		log:   log,
+		s:     s,
+		peers: peers,
+		done:  make(chan struct{}),
+	}
+	c.removeHook = s.AddPreShutdownHook(c.shutdownPeers)
+	for _, p := range peers {
+		if p.WakeTime != "" {
+			go c.wakeDaily(p)
+		}
+	}
+	return c, nil
+}
+
+// Close stops coordinating peers
+func (c *Coordinator) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.removeHook()
+	})
+}
+
+// shutdownPeers in parallel before this machine powers off, so a slow peer does not hold up the others.
+// It returns once all peers are asked or ctx is done. Shutdown requested by peer is not forwarded again,
+// so peers listing each other do not bounce it back and forth
+func (c *Coordinator) shutdownPeers(ctx context.Context, r shutd.ShutdownRequest) error {
+	if r.Source == shutd.SourcePeer {
+		c.log.Info("shutdown requested by peer: peers are not asked to shut down")
+		return nil
+	}
+	var wg sync.WaitGroup
+	for _, p := range c.peers {
+		if p.Addr == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(p Peer) {
+			defer wg.Done()
+			log := c.log.WithField("peer", p.String())
+			err := p.Shutdown()
+			if err != nil {
+				log.WithError(err).Error("failed to shut down peer")
+				return
+			}
+			log.Info("peer shutdown requested")
+		}(p)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("peers are not all asked to shut down: %w", ctx.Err())
+	}
+}
+
+func (c *Coordinator) wakeDaily(p Peer) {
+	log := c.log.WithField("peer", p.String())
+	for {
+		// wake time is validated by NewCoordinator
+		next, _ := shutd.NextClockTime(time.Now(), p.WakeTime)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			err := p.Wake()
+			if err != nil {
+				log.WithError(err).Error("failed to wake peer")
+				continue
+			}
+			log.Info("peer woken up")
+		case <-c.done:
+			timer.Stop()
+			return
+		}
+	}
+}
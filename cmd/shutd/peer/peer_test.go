@@ -0,0 +1,186 @@
+package peer
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/horacehylee/shutd"
+	"github.com/horacehylee/shutd/cmd/shutd/instance"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func listenUDP(t *testing.T) *net.UDPConn {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestMagicPacket(t *testing.T) {
+	packet, err := MagicPacket("01:23:45:67:89:ab")
+	assert.NoError(t, err)
+	assert.Len(t, packet, 102)
+	assert.Equal(t, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, packet[:6])
+	for i := 6; i < len(packet); i += 6 {
+		assert.Equal(t, []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab}, packet[i:i+6])
+	}
+
+	_, err = MagicPacket("invalid")
+	assert.EqualError(t, err, `invalid MAC address "invalid": address invalid: invalid MAC address`)
+}
+
+func TestSendMagicPacket(t *testing.T) {
+	conn := listenUDP(t)
+
+	err := SendMagicPacket("01-23-45-67-89-AB", conn.LocalAddr().String())
+	assert.NoError(t, err)
+
+	b := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFromUDP(b)
+	assert.NoError(t, err)
+	expected, _ := MagicPacket("01:23:45:67:89:ab")
+	assert.Equal(t, expected, b[:n])
+}
+
+func TestPeerWake(t *testing.T) {
+	conn := listenUDP(t)
+
+	err := Peer{Name: "nas"}.Wake()
+	assert.EqualError(t, err, "peer nas has no MAC address")
+
+	err = Peer{Name: "nas", MAC: "01:23:45:67:89:ab", Broadcast: conn.LocalAddr().String()}.Wake()
+	assert.NoError(t, err)
+}
+
+func TestCoordinatorShutdownPeers(t *testing.T) {
+	requests := make(chan []string, 1)
+	ln, err := instance.ServeAddr("127.0.0.1:0", "secret", func(args []string) (string, error) {
+		requests <- args
+		return "", nil
+	})
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	s, err := shutd.NewScheduler(shutd.Config{StartTime: "01:00"}, shutd.WithShutdownTask(func(s *shutd.Scheduler, r shutd.ShutdownRequest) error {
+		return nil
+	}))
+	assert.NoError(t, err)
+	defer s.Close(context.Background())
+	c, err := NewCoordinator(logrus.New(), s, []Peer{
+		{Name: "nas", Addr: ln.(net.Listener).Addr().String(), Token: "secret"},
+		{Name: "media", MAC: "01:23:45:67:89:ab"},
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	err = s.ShutdownNow()
+	assert.NoError(t, err)
+
+	// peers are asked before the shutdown task returns
+	select {
+	case args := <-requests:
+		assert.Equal(t, []string{"shutdown"}, args)
+	default:
+		t.Fatal("peer was not asked to shut down")
+	}
+}
+
+func TestCoordinatorShutdownPeersIsBounded(t *testing.T) {
+	release := make(chan struct{})
+	ln, err := instance.ServeAddr("127.0.0.1:0", "secret", func(args []string) (string, error) {
+		<-release
+		return "", nil
+	})
+	assert.NoError(t, err)
+	defer ln.Close()
+	defer close(release)
+
+	s, err := shutd.NewScheduler(shutd.Config{StartTime: "01:00"})
+	assert.NoError(t, err)
+	defer s.Close(context.Background())
+	c, err := NewCoordinator(logrus.New(), s, []Peer{{Name: "nas", Addr: ln.(net.Listener).Addr().String(), Token: "secret"}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = c.shutdownPeers(ctx, shutd.ShutdownRequest{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestCoordinatorDoesNotForwardShutdownOfPeer(t *testing.T) {
+	requests := make(chan []string, 1)
+	ln, err := instance.ServeAddr("127.0.0.1:0", "secret", func(args []string) (string, error) {
+		requests <- args
+		return "", nil
+	})
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	s, err := shutd.NewScheduler(shutd.Config{StartTime: "01:00"}, shutd.WithShutdownTask(func(s *shutd.Scheduler, r shutd.ShutdownRequest) error {
+		return nil
+	}))
+	assert.NoError(t, err)
+	defer s.Close(context.Background())
+	c, err := NewCoordinator(logrus.New(), s, []Peer{{Name: "nas", Addr: ln.(net.Listener).Addr().String(), Token: "secret"}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	err = s.ShutdownForPeer()
+	assert.NoError(t, err)
+
+	select {
+	case <-requests:
+		t.Fatal("shutdown of peer should not be forwarded back to peers")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestCoordinatorDryRunDoesNotShutdownPeers(t *testing.T) {
+	requests := make(chan []string, 1)
+	ln, err := instance.ServeAddr("127.0.0.1:0", "secret", func(args []string) (string, error) {
+		requests <- args
+		return "", nil
+	})
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	s, err := shutd.NewScheduler(shutd.Config{StartTime: "01:00", DryRun: true})
+	assert.NoError(t, err)
+	defer s.Close(context.Background())
+	c, err := NewCoordinator(logrus.New(), s, []Peer{
+		{Name: "nas", Addr: ln.(net.Listener).Addr().String(), Token: "secret"},
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	err = s.ShutdownNow()
+	assert.NoError(t, err)
+
+	select {
+	case <-requests:
+		t.Fatal("peer should not be asked to shut down in dry run")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestNewCoordinatorInvalidWakeTime(t *testing.T) {
+	s, err := shutd.NewScheduler(shutd.Config{StartTime: "01:00"})
+	assert.NoError(t, err)
+	defer s.Close(context.Background())
+
+	_, err = NewCoordinator(logrus.New(), s, []Peer{{Name: "nas", WakeTime: "7am"}})
+	assert.EqualError(t, err, "invalid wake time of peer nas: the given time format is not supported")
+
+	c, err := NewCoordinator(logrus.New(), s, []Peer{{Name: "nas", WakeTime: "07:30:00"}})
+	assert.NoError(t, err)
+	c.Close()
+}
@@ -0,0 +1,51 @@
+package peer
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+)
+
+const defaultBroadcast = "255.255.255.255:9"
+
+// MagicPacket for waking up machine with the MAC address, 6 bytes of 0xFF followed by 16 repetitions of MAC address
+func MagicPacket(mac string) ([]byte, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAC address %q: %w", mac, err)
+	}
+	if len(hw) != 6 {
+		return nil, fmt.Errorf("invalid MAC address %q: must be 6 bytes", mac)
+	}
+	var b bytes.Buffer
+	b.Write(bytes.Repeat([]byte{0xFF}, 6))
+	for i := 0; i < 16; i++ {
+		b.Write(hw)
+	}
+	return b.Bytes(), nil
+}
+
+// SendMagicPacket to broadcast address over UDP, broadcast defaults to 255.255.255.255:9
+func SendMagicPacket(mac string, broadcast string) error {
+	packet, err := MagicPacket(mac)
+	if err != nil {
+		return err
+	}
+	if broadcast == "" {
+		broadcast = defaultBroadcast
+	}
+	addr, err := net.ResolveUDPAddr("udp", broadcast)
+	if err != nil {
+		return fmt.Errorf("invalid broadcast address %q: %w", broadcast, err)
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return fmt.Errorf("failed to dial broadcast address: %w", err)
+	}
+	defer conn.Close()
+	_, err = conn.Write(packet)
+	if err != nil {
+		return fmt.Errorf("failed to send magic packet: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/horacehylee/shutd"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+// runHashPIN prints bcrypt hash of PIN for protect.pinHash
+func runHashPIN(log *logrus.Logger, args []string) error {
+	pin, err := readPIN("PIN: ")
+	if err != nil {
+		return err
+	}
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		confirm, err := readPIN("Confirm PIN: ")
+		if err != nil {
+			return err
+		}
+		if confirm != pin {
+			return fmt.Errorf("PINs do not match")
+		}
+	}
+	hash, err := shutd.HashPIN(pin)
+	if err != nil {
+		return err
+	}
+	fmt.Println(hash)
+	return nil
+}
+
+// readPIN without echo from terminal, or a line from piped stdin
+func readPIN(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read PIN: %w", err)
+		}
+		return strings.TrimSpace(line), nil
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read PIN: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// pinFlag removes "--pin 1234" from args, PIN is forwarded this way after asked on terminal.
+// It is appended after other args, e.g. "at 23:15 --pin 1234", so it is not parsed by flag package
+func pinFlag(args []string) ([]string, string) {
+	for i, arg := range args {
+		if (arg == "--pin" || arg == "-pin") && i+1 < len(args) {
+			return append(append([]string(nil), args[:i]...), args[i+2:]...), args[i+1]
+		}
+		for _, prefix := range []string{"--pin=", "-pin="} {
+			if strings.HasPrefix(arg, prefix) {
+				return append(append([]string(nil), args[:i]...), args[i+1:]...), strings.TrimPrefix(arg, prefix)
+			}
+		}
+	}
+	return args, ""
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/horacehylee/shutd"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// policy of administrator read from system-wide file, it is applied on top of user config
+type policy struct {
+	log *logrus.Logger
+	// v is only read on start and by its watcher, as viper is not safe for concurrent use
+	v *viper.Viper
+	// mu guards current, which is applied while user config is reloaded by another watcher
+	mu      sync.Mutex
+	current shutd.Policy
+	// changed is signalled once reloaded policy is applied, e.g. to refresh the tray
+	changed chan struct{}
+}
+
+// policyPath is /etc/shutd/policy.yaml, or %ProgramData%\shutd\policy.yaml on Windows
+func policyPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "shutd", "policy.yaml")
+	}
+	return "/etc/shutd/policy.yaml"
+}
+
+func newPolicy(log *logrus.Logger) *policy {
+	v := viper.New()
+	v.SetConfigFile(policyPath())
+	v.SetConfigType("yaml")
+	err := v.ReadInConfig()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatal(fmt.Errorf("could not read policy: %w", err))
+	}
+	if err == nil {
+		log.WithField("file", v.ConfigFileUsed()).Info("Policy loaded")
+	}
+	p := &policy{log: log, v: v, changed: make(chan struct{}, 1)}
+	err = p.parse()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return p
+}
+
+// parse policy read by viper as the current one, durations are decoded the same as config
+func (p *policy) parse() error {
+	var pol shutd.Policy
+	err := p.v.Unmarshal(&pol, viper.DecodeHook(shutd.ConfigDecodeHook()))
+	if err != nil {
+		return fmt.Errorf("failed to parse policy: %w", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = pol
+	return nil
+}
+
+// apply policy on top of config, invalid policy is reported when the config is validated by scheduler
+func (p *policy) apply(config shutd.Config) shutd.Config {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.current.Apply(config)
+}
+
+// watch policy file for changes, policy directory has to exist on start
+func (p *policy) watch(onChange func()) {
+	if _, err := os.Stat(filepath.Dir(p.v.ConfigFileUsed())); err != nil {
+		return
+	}
+	p.v.OnConfigChange(func(e fsnotify.Event) {
+		p.log.WithField("file", e.Name).Info("Policy file changed")
+		err := p.parse()
+		if err != nil {
+			p.log.WithError(err).Error("Previous policy is kept")
+			return
+		}
+		onChange()
+		select {
+		case p.changed <- struct{}{}:
+		default:
+			// refresh is pending already
+		}
+	})
+	p.v.WatchConfig()
+}
+
+// policyLocks describes settings locked by policy
+func policyLocks(p shutd.Policy) []string {
+	var locks []string
+	if p.LatestStartTime != "" {
+		locks = append(locks, fmt.Sprintf("Shutdown by %v", p.LatestStartTime))
+	}
+	if p.MaxSnoozes > 0 {
+		locks = append(locks, fmt.Sprintf("Snooze up to %v times", p.MaxSnoozes))
+	}
+	if p.DisableSkip {
+		locks = append(locks, "Skip and pause are disabled")
+	}
+	if p.Protect.PINHash != "" {
+		locks = append(locks, "PIN is set")
+	}
+	switch p.Action {
+	case shutd.ActionShutdown:
+		locks = append(locks, "Dry run is disabled")
+	case shutd.ActionDryRun:
+		locks = append(locks, "Dry run is forced")
+	}
+	return locks
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/horacehylee/shutd/cmd/shutd/profile"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// profileSwitchTimeout for the config watcher to apply the switched profile
+const profileSwitchTimeout = 5 * time.Second
+
+// profiles switches active profile of config file, the config is applied by the config watcher
+type profiles struct {
+	log *logrus.Logger
+	// file of config, where the active profile is persisted
+	file string
+	// configure with configMu held
+	configure func(user userConfig)
+	// current config applied, guarded by configMu
+	current userConfig
+}
+
+// apply user config with configMu held
+func (p *profiles) apply(user userConfig) {
+	if user.active != p.current.active {
+		p.log.WithField("profile", user.active).Info("Profile switched")
+	}
+	p.current = user
+	p.configure(user)
+}
+
+// profileSettings of the named profile from viper, profile names are case insensitive as viper lowercases keys
+func profileSettings(name string) (map[string]interface{}, bool) {
+	for n, settings := range viper.GetStringMap("profiles") {
+		if strings.EqualFold(n, name) {
+			m, ok := settings.(map[string]interface{})
+			return m, ok
+		}
+	}
+	return nil, false
+}
+
+func profileNames() []string {
+	return profile.Names(viper.GetStringMap("profiles"))
+}
+
+func activeProfile() string {
+	return strings.ToLower(viper.GetString(profile.ActiveKey))
+}
+
+// names of profiles and the active one
+func (p *profiles) names() ([]string, string) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	return p.current.profiles, p.current.active
+}
+
+// switchTo the profile, it is persisted to config file and waited for the config watcher to apply it,
+// so viper is not read outside of its watcher
+func (p *profiles) switchTo(name string) error {
+	names, _ := p.names()
+	name = strings.ToLower(name)
+	if !contains(names, name) {
+		return fmt.Errorf("unknown profile %q, available profiles: %v", name, strings.Join(names, ", "))
+	}
+	err := profile.SetActive(p.file, name)
+	if err != nil {
+		return err
+	}
+	timeout := time.After(profileSwitchTimeout)
+	for {
+		if _, active := p.names(); active == name {
+			return nil
+		}
+		select {
+		case <-time.After(50 * time.Millisecond):
+		case <-timeout:
+			return fmt.Errorf("profile %q is saved but not applied yet", name)
+		}
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// list profiles with the active one marked
+func (p *profiles) list() string {
+	names, active := p.names()
+	if len(names) == 0 {
+		return "No profiles"
+	}
+	lines := make([]string, len(names))
+	for i, name := range names {
+		mark := " "
+		if name == active {
+			mark = "*"
+		}
+		lines[i] = fmt.Sprintf("%v %v", mark, name)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,108 @@
+// Package profile applies named profiles on top of config settings, and persists the active profile to config file
+package profile
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ActiveKey of config file for name of the active profile
+const ActiveKey = "activeProfile"
+
+// Merge profile settings on top of base settings, nested settings are merged while others are replaced
+func Merge(base, profile map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range profile {
+		key := k
+		// keys are case insensitive as viper
+		for b := range merged {
+			if strings.EqualFold(b, k) {
+				key = b
+				break
+			}
+		}
+		baseMap, baseOk := toStringMap(merged[key])
+		profileMap, profileOk := toStringMap(v)
+		if baseOk && profileOk {
+			merged[key] = Merge(baseMap, profileMap)
+			continue
+		}
+		merged[key] = v
+	}
+	return merged
+}
+
+func toStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			converted[fmt.Sprint(k)] = v
+		}
+		return converted, true
+	}
+	return nil, false
+}
+
+// Names of profiles in alphabetical order
+func Names(profiles map[string]interface{}) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetActive writes name of the active profile to YAML config file at path, comments and other settings are kept
+func SetActive(path, name string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	var doc yaml.Node
+	err = yaml.Unmarshal(b, &doc)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config is not a mapping")
+	}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if strings.EqualFold(root.Content[i].Value, ActiveKey) {
+			root.Content[i+1] = value
+			found = true
+		}
+	}
+	if !found {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ActiveKey}, value)
+	}
+
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	err = os.WriteFile(path, []byte(out.String()), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
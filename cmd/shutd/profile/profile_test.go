@@ -0,0 +1,96 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	base := map[string]interface{}{
+		"starttime":  "01:00",
+		"maxsnoozes": 3,
+		"notification": map[string]interface{}{
+			"before":   "10m",
+			"duration": "10m",
+		},
+		"skipdates": []interface{}{"2026-12-24"},
+	}
+	profile := map[string]interface{}{
+		"startTime": "03:00",
+		"notification": map[interface{}]interface{}{
+			"before": "5m",
+		},
+		"skipdates": []interface{}{},
+	}
+	merged := Merge(base, profile)
+	assert.Equal(t, map[string]interface{}{
+		"starttime":  "03:00",
+		"maxsnoozes": 3,
+		"notification": map[string]interface{}{
+			"before":   "5m",
+			"duration": "10m",
+		},
+		"skipdates": []interface{}{},
+	}, merged)
+	// base is not modified
+	assert.Equal(t, "01:00", base["starttime"])
+	assert.Equal(t, "10m", base["notification"].(map[string]interface{})["before"])
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, []string{"crunch", "vacation", "work"}, Names(map[string]interface{}{
+		"work":     nil,
+		"crunch":   nil,
+		"vacation": nil,
+	}))
+	assert.Empty(t, Names(nil))
+}
+
+func TestSetActive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".shutd.yaml")
+	config := `# shutdown every night
+startTime: "01:00"
+profiles:
+  work:
+    startTime: "19:00" # after office hours
+  crunch:
+    startTime: "03:00"
+`
+	err := os.WriteFile(path, []byte(config), 0644)
+	assert.NoError(t, err)
+
+	err = SetActive(path, "work")
+	assert.NoError(t, err)
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, config+"activeProfile: work\n", string(b))
+
+	err = SetActive(path, "crunch")
+	assert.NoError(t, err)
+	b, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, config+"activeProfile: crunch\n", string(b))
+}
+
+func TestSetActiveOfEmptyConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".shutd.yaml")
+	err := os.WriteFile(path, nil, 0644)
+	assert.NoError(t, err)
+
+	err = SetActive(path, "work")
+	assert.NoError(t, err)
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "activeProfile: work\n", string(b))
+
+	err = SetActive(filepath.Join(t.TempDir(), "missing.yaml"), "work")
+	assert.Error(t, err)
+
+	err = os.WriteFile(path, []byte("- a\n- b\n"), 0644)
+	assert.NoError(t, err)
+	err = SetActive(path, "work")
+	assert.EqualError(t, err, "config is not a mapping")
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/horacehylee/shutd"
+	"github.com/sirupsen/logrus"
+)
+
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func runSimulate(log *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	days := flags.Int("days", 3, "number of days to simulate")
+	var snoozeAt stringsFlag
+	flags.Var(&snoozeAt, "snooze-at", "clock time (e.g. 00:50) to snooze at, can be repeated")
+	flags.Parse(args)
+
+	config := newPolicy(log).apply(readConfig(log).config)
+	events, err := shutd.Simulate(config, shutd.SimulateOptions{
+		From:     time.Now(),
+		Days:     *days,
+		SnoozeAt: snoozeAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to simulate: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, e := range events {
+		fmt.Fprintf(w, "%v\t%v\tshutdown at %v", e.Time.Format("Mon 2006-01-02 15:04"), e.Type, e.ShutdownTime.Format("15:04"))
+		if e.Reason != "" {
+			fmt.Fprintf(w, " (%v)", e.Reason)
+		}
+		fmt.Fprintln(w)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,97 @@
+// Package systemd implements sd_notify protocol for readiness and watchdog of systemd services
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// Ready tells systemd that service startup is finished
+	Ready = "READY=1"
+	// Stopping tells systemd that service is beginning its shutdown
+	Stopping = "STOPPING=1"
+	// Watchdog keep-alive ping
+	Watchdog = "WATCHDOG=1"
+)
+
+// Notify sends state to systemd via $NOTIFY_SOCKET, returns false if not running under systemd
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// abstract namespace socket
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect notify socket: %w", err)
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	if err != nil {
+		return false, fmt.Errorf("failed to notify systemd: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns interval that watchdog ping is expected, returns false if watchdog is not enabled for this process
+func WatchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+	return time.Duration(usec) * time.Microsecond, true
+}
+
+// StartWatchdog pings systemd watchdog at half of the expected interval until stop is closed. Ping is only sent
+// once healthy returns nil within a quarter of the interval, so systemd restarts the service when it is stuck
+func StartWatchdog(stop <-chan struct{}, healthy func() error, onError func(error)) bool {
+	interval, ok := WatchdogInterval()
+	if !ok {
+		return false
+	}
+	go func() {
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+		// pending health check, it is waited for again instead of piling up checks while stuck
+		var pending chan error
+		for {
+			select {
+			case <-ticker.C:
+				if pending == nil {
+					pending = make(chan error, 1)
+					go func(result chan<- error) {
+						result <- healthy()
+					}(pending)
+				}
+				var err error
+				select {
+				case err = <-pending:
+					pending = nil
+				case <-time.After(interval / 4):
+					err = fmt.Errorf("health check is not done within %v", interval/4)
+				}
+				if err != nil {
+					onError(fmt.Errorf("unhealthy, watchdog is not pinged: %w", err))
+					continue
+				}
+				_, err = Notify(Watchdog)
+				if err != nil {
+					onError(err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return true
+}
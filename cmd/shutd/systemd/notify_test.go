@@ -0,0 +1,102 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listenNotifySocket(t *testing.T) *net.UnixConn {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	assert.NoError(t, err)
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+func readState(t *testing.T, conn *net.UnixConn) string {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	b := make([]byte, 256)
+	n, err := conn.Read(b)
+	assert.NoError(t, err)
+	return string(b[:n])
+}
+
+func TestNotify(t *testing.T) {
+	conn := listenNotifySocket(t)
+	defer conn.Close()
+
+	ok, err := Notify(Ready)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "READY=1", readState(t, conn))
+}
+
+func TestNotifyWithoutSystemd(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	ok, err := Notify(Ready)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	_, ok := WatchdogInterval()
+	assert.False(t, ok)
+
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	interval, ok := WatchdogInterval()
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, interval)
+
+	t.Setenv("WATCHDOG_PID", "1")
+	_, ok = WatchdogInterval()
+	assert.False(t, ok)
+}
+
+func TestStartWatchdog(t *testing.T) {
+	conn := listenNotifySocket(t)
+	defer conn.Close()
+	t.Setenv("WATCHDOG_USEC", "100000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	stop := make(chan struct{})
+	defer close(stop)
+	ok := StartWatchdog(stop, func() error { return nil }, func(err error) { t.Error(err) })
+	assert.True(t, ok)
+	assert.Equal(t, "WATCHDOG=1", readState(t, conn))
+}
+
+func TestStartWatchdogWhileStuck(t *testing.T) {
+	conn := listenNotifySocket(t)
+	defer conn.Close()
+	t.Setenv("WATCHDOG_USEC", "100000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	stop := make(chan struct{})
+	defer close(stop)
+	stuck := make(chan struct{})
+	defer close(stuck)
+	errs := make(chan error, 10)
+	ok := StartWatchdog(stop, func() error {
+		<-stuck
+		return nil
+	}, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+	assert.True(t, ok)
+
+	conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	_, err := conn.Read(make([]byte, 256))
+	assert.Error(t, err, "watchdog should not be pinged while stuck")
+	assert.Error(t, <-errs)
+}
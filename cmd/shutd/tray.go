@@ -0,0 +1,191 @@
+//go:build !nosystray
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gen2brain/dlgs"
+	"github.com/getlantern/systray"
+	"github.com/horacehylee/shutd"
+	"github.com/horacehylee/shutd/cmd/shutd/icon"
+	"github.com/horacehylee/shutd/cmd/shutd/instance"
+	"github.com/sirupsen/logrus"
+)
+
+func startSystray(log *logrus.Logger, s *shutd.Scheduler, lock *instance.Lock, profiles *profiles, policy *policy) {
+	onReady := func() {
+		systray.SetTemplateIcon(icon.Data, icon.Data)
+		systray.SetTitle("Shutd")
+		systray.SetTooltip("Shutd")
+		shutdownTimeItem := systray.AddMenuItem("Shutdown at ?", "Shutdown at ?")
+		wakeTimeItem := systray.AddMenuItem("Wake at ?", "Wake at ?")
+		systray.AddSeparator()
+		snoozeItem := systray.AddMenuItem("Snooze", "Snooze shutdown")
+		profileItems := addProfileMenu(log, s, profiles)
+		quitItem := systray.AddMenuItem("Quit", "Quit the whole app")
+
+		shutdownTimeItem.Disable()
+		wakeTimeItem.Disable()
+		wakeTimeItem.Hide()
+		annotateProfileItems(profileItems, s.Config().Policy)
+
+		go func() {
+			var shutdownTitle string
+			for {
+				select {
+				case t, ok := <-s.ShutdownTimeChangedChan():
+					if !ok {
+						// scheduler is closed
+						return
+					}
+					config := s.Config()
+					shutdownTitle = fmt.Sprintf("Shutdown at %v", t.Format("15:04"))
+					tooltip := "Shutd"
+					if config.DryRun {
+						shutdownTitle = fmt.Sprintf("Dry run - %v", shutdownTitle)
+						tooltip = "Shutd (dry run)"
+					}
+					shutdownTimeItem.SetTitle(shutdownTitle)
+					shutdownTimeItem.SetTooltip(withPolicyLocks(shutdownTitle, config.Policy))
+					systray.SetTooltip(tooltip)
+					refreshSnoozeItem(s, snoozeItem)
+					if wakeTime, ok := s.WakeTime(); ok {
+						wakeTitle := fmt.Sprintf("Wake at %v", wakeTime.Format("15:04"))
+						wakeTimeItem.SetTitle(wakeTitle)
+						wakeTimeItem.SetTooltip(wakeTitle)
+						wakeTimeItem.Show()
+					} else {
+						wakeTimeItem.Hide()
+					}
+				case <-snoozeItem.ClickedCh:
+					// PIN dialog does not block updates of the menu
+					go func() {
+						err := withPINDialog(s, "snooze", s.SnoozeWithPIN)
+						if err != nil {
+							log.Errorf("failed to snooze: %v", err)
+						}
+					}()
+				case <-policy.changed:
+					p := s.Config().Policy
+					shutdownTimeItem.SetTooltip(withPolicyLocks(shutdownTitle, p))
+					refreshSnoozeItem(s, snoozeItem)
+					annotateProfileItems(profileItems, p)
+				case <-quitItem.ClickedCh:
+					systray.Quit()
+					return
+				}
+			}
+		}()
+	}
+
+	onExit := func() {
+		exit(log, s, lock)
+	}
+	systray.Run(onReady, onExit)
+}
+
+// withPINDialog runs action with PIN asked by password dialog until it is correct when protected
+func withPINDialog(s *shutd.Scheduler, name string, action func(pin string) error) error {
+	if !s.Protected() {
+		return action("")
+	}
+	text := fmt.Sprintf("Enter PIN to %v", name)
+	for {
+		pin, ok, err := dlgs.Password("Shutd", text)
+		if err != nil {
+			return fmt.Errorf("failed to ask for PIN: %w", err)
+		}
+		if !ok || pin == "" {
+			return nil
+		}
+		err = action(pin)
+		if !errors.Is(err, shutd.ErrWrongPIN) {
+			return err
+		}
+		text = fmt.Sprintf("Wrong PIN, enter PIN to %v", name)
+	}
+}
+
+// withPolicyLocks appends settings locked by policy to tooltip
+func withPolicyLocks(tooltip string, p shutd.Policy) string {
+	locks := policyLocks(p)
+	if len(locks) == 0 {
+		return tooltip
+	}
+	return fmt.Sprintf("%v\nLocked by policy: %v", tooltip, strings.Join(locks, ", "))
+}
+
+// refreshSnoozeItem disables snooze once it is not allowed, e.g. snooze limit is reached or policy forbids delay
+func refreshSnoozeItem(s *shutd.Scheduler, item *systray.MenuItem) {
+	err := s.CanSnooze()
+	switch {
+	case err == nil:
+		item.SetTitle("Snooze")
+		item.SetTooltip(withPolicyLocks("Snooze shutdown", s.Config().Policy))
+		item.Enable()
+		return
+	case errors.Is(err, shutd.ErrSnoozeLimitReached):
+		item.SetTitle("Snooze (limit reached)")
+	case errors.Is(err, shutd.ErrNotAllowed):
+		item.SetTitle("Snooze (locked by policy)")
+	default:
+		item.SetTitle("Snooze (unavailable)")
+	}
+	item.SetTooltip(withPolicyLocks(err.Error(), s.Config().Policy))
+	item.Disable()
+}
+
+// annotateProfileItems with settings locked by policy, which switching profile does not change
+func annotateProfileItems(items map[string]*systray.MenuItem, p shutd.Policy) {
+	for name, item := range items {
+		item.SetTooltip(withPolicyLocks(fmt.Sprintf("Switch to %v profile", name), p))
+	}
+}
+
+// addProfileMenu with profiles of config file, profiles added later are shown after restart.
+// It returns the profile items by name, nil without profiles
+func addProfileMenu(log *logrus.Logger, s *shutd.Scheduler, profiles *profiles) map[string]*systray.MenuItem {
+	names, active := profiles.names()
+	if len(names) == 0 {
+		return nil
+	}
+	profileItem := systray.AddMenuItem("Profile", "Switch profile")
+	items := make([]*systray.MenuItem, len(names))
+	for i, name := range names {
+		items[i] = profileItem.AddSubMenuItemCheckbox(name, fmt.Sprintf("Switch to %v profile", name), name == active)
+	}
+	for i, item := range items {
+		go func(name string, item *systray.MenuItem) {
+			for range item.ClickedCh {
+				switched := false
+				err := withPINDialog(s, "switch profile", func(pin string) error {
+					err := s.VerifyPIN(pin)
+					if err != nil {
+						return err
+					}
+					switched = true
+					return profiles.switchTo(name)
+				})
+				if err != nil {
+					log.Errorf("failed to switch profile: %v", err)
+					continue
+				}
+				if !switched {
+					continue
+				}
+				for _, other := range items {
+					other.Uncheck()
+				}
+				item.Check()
+			}
+		}(names[i], item)
+	}
+	byName := make(map[string]*systray.MenuItem, len(items))
+	for i, item := range items {
+		byName[names[i]] = item
+	}
+	return byName
+}
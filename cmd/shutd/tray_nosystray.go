@@ -0,0 +1,15 @@
+//go:build nosystray
+
+package main
+
+import (
+	"github.com/horacehylee/shutd"
+	"github.com/horacehylee/shutd/cmd/shutd/instance"
+	"github.com/sirupsen/logrus"
+)
+
+// startSystray falls back to headless mode when built without systray support
+func startSystray(log *logrus.Logger, s *shutd.Scheduler, lock *instance.Lock, profiles *profiles, policy *policy) {
+	log.Warn("built without systray, running headless")
+	runHeadless(log, s)
+}
@@ -0,0 +1,103 @@
+package shutd
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertJobsConsistent checks snooze notification is scheduled before shutdown by the configured duration
+func assertJobsConsistent(t *testing.T, s *Scheduler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shutdownTime := s.shutdownJob.ScheduledTime()
+	notifyTime := s.snoozeNotificationJob.ScheduledTime()
+	assert.Equal(t, notificationTime(shutdownTime, s.config).Format("15:04"), notifyTime.Format("15:04"))
+	start, err := parseClockTime(s.config.StartTime)
+	assert.NoError(t, err)
+	snoozed := time.Duration(s.snoozeCount) * s.config.SnoozeInterval
+	assert.Equal(t, (start+snoozed)%(24*time.Hour), sinceMidnight(shutdownTime))
+}
+
+// startTimeFromNow in 15:04, so snoozed shutdowns of the tests are hours away and their notifications are not past due,
+// which would be started immediately instead
+func startTimeFromNow(d time.Duration) string {
+	return time.Now().Add(d).Format("15:04")
+}
+
+func TestConcurrentSnoozeIsNotLost(t *testing.T) {
+	config := getConfigWithShutdownTime(startTimeFromNow(6 * time.Hour))
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+
+	const snoozes = 20
+	var wg sync.WaitGroup
+	for i := 0; i < snoozes; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, s.Snooze())
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, snoozes, s.SnoozeCount())
+	shutdownTime, err := s.ShutdownTime()
+	assert.NoError(t, err)
+	start, _ := time.Parse("15:04", config.StartTime)
+	assert.Equal(t, start.Add(snoozes*config.SnoozeInterval).Format("15:04"), shutdownTime.Format("15:04"))
+	assertJobsConsistent(t, s)
+}
+
+func TestConcurrentConfigureAndSnooze(t *testing.T) {
+	battery := &fakeBattery{state: BatteryState{Percent: 80, OnAC: true}}
+	config := getConfigWithShutdownTime(startTimeFromNow(6 * time.Hour))
+	config.Battery = BatteryConfig{ShutdownBelow: 10, SkipWhileCharging: true, PollInterval: time.Millisecond}
+	s, err := getSchedulerWithConfig(t, config, WithBattery(battery))
+	assert.NoError(t, err)
+
+	// 50 snoozes delay up to 12.5h
+	starts := []string{startTimeFromNow(6 * time.Hour), startTimeFromNow(8*time.Hour + 30*time.Minute), startTimeFromNow(5*time.Hour + 45*time.Minute)}
+	var wg sync.WaitGroup
+	run := func(n int, f func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				f(i)
+			}
+		}()
+	}
+	run(50, func(i int) {
+		c := config
+		c.StartTime = starts[i%3]
+		assert.NoError(t, s.Configure(c))
+	})
+	run(50, func(i int) {
+		assert.NoError(t, s.Snooze())
+	})
+	run(50, func(i int) {
+		assert.NoError(t, s.Skip())
+		assert.NoError(t, s.Pause())
+		assert.NoError(t, s.Resume())
+	})
+	run(50, func(i int) {
+		events := s.Subscribe()
+		s.ShutdownTime()
+		s.ShutdownRequest()
+		s.WakeTime()
+		s.SnoozeCount()
+		s.Skipped()
+		s.Paused()
+		s.Config()
+		s.Unsubscribe(events)
+	})
+	wg.Wait()
+
+	assertJobsConsistent(t, s)
+	assert.NoError(t, s.Configure(config))
+	assert.Equal(t, 0, s.SnoozeCount())
+	assertJobsConsistent(t, s)
+}
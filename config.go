@@ -0,0 +1,166 @@
+package shutd
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Config for shutdown scheduler
+type Config struct {
+	SnoozeInterval time.Duration
+	StartTime      string
+	// MaxSnoozes of each shutdown, 0 for unlimited
+	MaxSnoozes   int
+	Notification NotificationConfig
+	// WakeTime to power on the computer after shutdown, e.g. "07:30", empty to disable
+	WakeTime string
+	// DryRun runs notification and snooze flow without powering off the computer
+	DryRun bool
+	// Webhooks to post shutdown lifecycle events to
+	Webhooks []WebhookConfig
+	// MQTT to publish state and receive commands, e.g. for Home Assistant
+	MQTT MQTTConfig
+	// Battery to shut down on low battery or skip shutdown while charging
+	Battery BatteryConfig
+	// UPS to shut down when UPS is on battery
+	UPS UPSConfig
+	// Thermal to shut down when overheated
+	Thermal ThermalConfig
+	// Quiet to shut down when CPU and network are quiet
+	Quiet QuietConfig
+	// Process to shut down after watched process exits
+	Process ProcessConfig
+	// SkipDates to skip the shutdown on the night of, e.g. "2026-12-24"
+	SkipDates []string
+	// Calendar to skip or shift shutdowns by events, e.g. holidays
+	Calendar CalendarConfig
+	// Protect snooze, skip and pause with PIN
+	Protect ProtectConfig
+	// Policy applied by Policy.Apply, it could not be set by user config
+	Policy Policy `mapstructure:"-"`
+}
+
+// NotificationConfig for snooze notification before shutdown
+type NotificationConfig struct {
+	Before   time.Duration
+	Duration time.Duration
+	// Notifier name to ask for snooze, e.g. dialog, wall or terminal
+	Notifier string
+}
+
+// validate config before scheduling, so errors are reported as ConfigError rather than errors of gocron
+func (c Config) validate() error {
+	_, err := parseClockTime(c.StartTime)
+	if err != nil {
+		return &ConfigError{Field: "startTime", Value: c.StartTime, Err: err}
+	}
+	if c.WakeTime != "" {
+		_, err = parseClockTime(c.WakeTime)
+		if err != nil {
+			return &ConfigError{Field: "wakeTime", Value: c.WakeTime, Err: err}
+		}
+	}
+	for _, d := range c.SkipDates {
+		_, err = time.Parse(skipDateLayout, d)
+		if err != nil {
+			return &ConfigError{Field: "skipDates", Value: d, Err: ErrInvalidTime}
+		}
+	}
+	_, err = regexp.Compile(c.Calendar.SkipOnEventsMatching)
+	if err != nil {
+		return &ConfigError{Field: "calendar.skipOnEventsMatching", Value: c.Calendar.SkipOnEventsMatching, Err: err}
+	}
+	if c.MaxSnoozes < 0 {
+		return &ConfigError{Field: "maxSnoozes", Value: c.MaxSnoozes, Err: fmt.Errorf("must not be negative")}
+	}
+	err = c.Protect.validate()
+	if err != nil {
+		return err
+	}
+	return c.Policy.validate()
+}
+
+// locked returns whether shutdowns could not be delayed at will, as restricted by policy or PIN
+func (c Config) locked() bool {
+	return c.Policy.active() || c.Protect.enabled()
+}
+
+// redacted copy of config without secrets, for logging
+func (c Config) redacted() Config {
+	if c.MQTT.Password != "" {
+		c.MQTT.Password = "***"
+	}
+	if c.UPS.Password != "" {
+		c.UPS.Password = "***"
+	}
+	if c.Protect.PINHash != "" {
+		c.Protect.PINHash = "***"
+	}
+	if c.Policy.Protect.PINHash != "" {
+		c.Policy.Protect.PINHash = "***"
+	}
+	webhooks := make([]WebhookConfig, len(c.Webhooks))
+	for i, w := range c.Webhooks {
+		if len(w.Headers) > 0 {
+			headers := make(map[string]string, len(w.Headers))
+			for k := range w.Headers {
+				headers[k] = "***"
+			}
+			w.Headers = headers
+		}
+		webhooks[i] = w
+	}
+	c.Webhooks = webhooks
+	return c
+}
+
+// DurationDecodeHook decodes Go duration strings (e.g. "90s", "1h30m") into time.Duration,
+// bare numbers are treated as minutes for backward compatibility
+func DurationDecodeHook() mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if t != reflect.TypeOf(time.Duration(0)) {
+			return data, nil
+		}
+		switch v := data.(type) {
+		case int:
+			return time.Duration(v) * time.Minute, nil
+		case int64:
+			return time.Duration(v) * time.Minute, nil
+		case uint64:
+			return time.Duration(v) * time.Minute, nil
+		case float64:
+			return time.Duration(v * float64(time.Minute)), nil
+		case string:
+			if m, err := strconv.ParseFloat(v, 64); err == nil {
+				return time.Duration(m * float64(time.Minute)), nil
+			}
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid duration %q: %v", v, err)
+			}
+			return d, nil
+		}
+		return data, nil
+	}
+}
+
+// ConfigDecodeHook for decoding Config with viper or mapstructure
+func ConfigDecodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		DurationDecodeHook(),
+		mapstructure.StringToSliceHookFunc(","),
+	)
+}
+
+// formatMinutes formats duration as minutes for display, falls back to Go duration format when not whole minutes
+func formatMinutes(d time.Duration) string {
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%d minutes", d/time.Minute)
+	}
+	return d.String()
+}
@@ -0,0 +1,97 @@
+package shutd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeConfig(t *testing.T, input map[string]interface{}) (Config, error) {
+	var config Config
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &config,
+		WeaklyTypedInput: true,
+		DecodeHook:       ConfigDecodeHook(),
+	})
+	assert.NoError(t, err)
+	err = decoder.Decode(input)
+	return config, err
+}
+
+func TestDecodeConfigWithBareMinutes(t *testing.T) {
+	config, err := decodeConfig(t, map[string]interface{}{
+		"startTime":      "01:00",
+		"snoozeInterval": 15,
+		"notification": map[string]interface{}{
+			"before":   "10",
+			"duration": 2.5,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, config.SnoozeInterval)
+	assert.Equal(t, 10*time.Minute, config.Notification.Before)
+	assert.Equal(t, 150*time.Second, config.Notification.Duration)
+}
+
+func TestDecodeConfigWithDurationStrings(t *testing.T) {
+	config, err := decodeConfig(t, map[string]interface{}{
+		"snoozeInterval": "1h30m",
+		"notification": map[string]interface{}{
+			"before":   "90s",
+			"duration": "5m",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, config.SnoozeInterval)
+	assert.Equal(t, 90*time.Second, config.Notification.Before)
+	assert.Equal(t, 5*time.Minute, config.Notification.Duration)
+}
+
+func TestDecodeConfigWithInvalidDuration(t *testing.T) {
+	_, err := decodeConfig(t, map[string]interface{}{
+		"snoozeInterval": "soon",
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid duration "soon"`)
+}
+
+func TestFormatMinutes(t *testing.T) {
+	assert.Equal(t, "15 minutes", formatMinutes(15*time.Minute))
+	assert.Equal(t, "1m30s", formatMinutes(90*time.Second))
+}
+
+func TestDecodeConfigWithWebhooks(t *testing.T) {
+	config, err := decodeConfig(t, map[string]interface{}{
+		"webhooks": []interface{}{
+			map[string]interface{}{
+				"url":     "http://localhost/hook",
+				"events":  []interface{}{"snoozed", "shutdown_started"},
+				"headers": map[string]interface{}{"authorization": "Bearer token"},
+				"timeout": "2s",
+				"retries": 0,
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, config.Webhooks, 1)
+	w := config.Webhooks[0]
+	assert.Equal(t, []EventType{Snoozed, ShutdownStarted}, w.Events)
+	assert.Equal(t, "Bearer token", w.Headers["authorization"])
+	assert.Equal(t, 2*time.Second, w.Timeout)
+	assert.Equal(t, 0, *w.Retries)
+}
+
+func TestRedactedConfig(t *testing.T) {
+	config := getDefaultConfig()
+	config.MQTT.Password = "secret"
+	config.Protect.PINHash = "hash"
+	config.Webhooks = []WebhookConfig{{URL: "http://localhost", Headers: map[string]string{"Authorization": "secret"}}}
+
+	redacted := config.redacted()
+	assert.Equal(t, "***", redacted.MQTT.Password)
+	assert.Equal(t, "***", redacted.Protect.PINHash)
+	assert.Equal(t, "***", redacted.Webhooks[0].Headers["Authorization"])
+	assert.Equal(t, "secret", config.Webhooks[0].Headers["Authorization"])
+}
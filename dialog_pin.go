@@ -0,0 +1,29 @@
+package shutd
+
+import (
+	"context"
+
+	"github.com/gen2brain/dlgs"
+)
+
+// askPIN with password dialog, empty PIN is returned when cancelled
+func askPIN(ctx context.Context, title, text string) (string, error) {
+	type result struct {
+		pin string
+		err error
+	}
+	chanResult := make(chan result, 1)
+	go func() {
+		pin, ok, err := dlgs.Password(title, text)
+		if !ok {
+			pin = ""
+		}
+		chanResult <- result{pin, err}
+	}()
+	select {
+	case res := <-chanResult:
+		return res.pin, res.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
@@ -0,0 +1,54 @@
+package shutd
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrClosed is returned when scheduler is used after Close
+	ErrClosed = errors.New("scheduler is closed")
+	// ErrNotScheduled is returned when shutdown job is not scheduled
+	ErrNotScheduled = errors.New("shutdown job is not scheduled")
+	// ErrInvalidTime is returned when clock time is not in 15:04 or 15:04:05 format
+	ErrInvalidTime = errors.New("the given time format is not supported")
+	// ErrSnoozeLimitReached is returned when shutdown is snoozed Config.MaxSnoozes times already
+	ErrSnoozeLimitReached = errors.New("snooze limit is reached")
+	// ErrNotSnoozable is returned when snoozing emergency shutdown, e.g. UPS on battery
+	ErrNotSnoozable = errors.New("shutdown cannot be snoozed")
+	// ErrNotAllowed is returned when the action is disallowed by Config.Policy, e.g. skip
+	ErrNotAllowed = errors.New("not allowed by policy")
+	// ErrPINRequired is returned when snooze, skip or pause is protected by Config.Protect and PIN is not given
+	ErrPINRequired = errors.New("PIN is required")
+	// ErrWrongPIN is returned when the given PIN does not match Config.Protect
+	ErrWrongPIN = errors.New("wrong PIN")
+	// ErrPINLocked is returned after too many wrong PINs until the lockout is over
+	ErrPINLocked = errors.New("too many wrong PINs")
+)
+
+// ConfigError for invalid value of config field, Err is the cause, e.g. ErrInvalidTime
+type ConfigError struct {
+	Field string
+	Value interface{}
+	Err   error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid %v %q: %v", e.Field, fmt.Sprint(e.Value), e.Err)
+}
+
+// Unwrap returns the cause
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ScheduleError when job could not be scheduled, Cause is kept as text so the scheduling library is not exposed
+type ScheduleError struct {
+	// Job tag, e.g. shutdown or battery
+	Job   string
+	Cause string
+}
+
+func (e *ScheduleError) Error() string {
+	return fmt.Sprintf("failed to schedule %v job: %v", e.Job, e.Cause)
+}
@@ -0,0 +1,55 @@
+package shutd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigErrorForInvalidStartTime(t *testing.T) {
+	s := getScheduler(t)
+	err := s.Configure(getConfigWithShutdownTime("32:00"))
+
+	var e *ConfigError
+	if assert.True(t, errors.As(err, &e)) {
+		assert.Equal(t, "startTime", e.Field)
+		assert.Equal(t, "32:00", e.Value)
+	}
+	assert.ErrorIs(t, err, ErrInvalidTime)
+	// scheduler keeps the previous schedule
+	shutdownTime, err := s.ShutdownTime()
+	assert.NoError(t, err)
+	assert.Equal(t, "00:00", shutdownTime.Format("15:04"))
+}
+
+func TestConfigErrorForNegativeMaxSnoozes(t *testing.T) {
+	config := getDefaultConfig()
+	config.MaxSnoozes = -1
+	_, err := getSchedulerWithConfig(t, config)
+	assert.EqualError(t, err, `invalid maxSnoozes "-1": must not be negative`)
+
+	var e *ConfigError
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, -1, e.Value)
+}
+
+func TestSnoozeLimitReached(t *testing.T) {
+	config := getDefaultConfig()
+	config.MaxSnoozes = 2
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Snooze())
+	assert.True(t, s.ShutdownRequest().Snoozable)
+	assert.NoError(t, s.CanSnooze())
+	assert.NoError(t, s.Snooze())
+	assert.False(t, s.ShutdownRequest().Snoozable)
+	assert.ErrorIs(t, s.CanSnooze(), ErrSnoozeLimitReached)
+
+	err = s.Snooze()
+	assert.ErrorIs(t, err, ErrSnoozeLimitReached)
+	assert.Equal(t, 2, s.SnoozeCount())
+	shutdownTime, _ := s.ShutdownTime()
+	assert.Equal(t, "00:30", shutdownTime.Format("15:04"))
+}
@@ -0,0 +1,84 @@
+package shutd
+
+import "time"
+
+// EventType of scheduler Event
+type EventType string
+
+const (
+	// ShutdownScheduled when shutdown time is scheduled or changed
+	ShutdownScheduled EventType = "scheduled"
+	// NotificationShown when snooze notification is shown
+	NotificationShown EventType = "notification_shown"
+	// Snoozed when shutdown is snoozed
+	Snoozed EventType = "snoozed"
+	// ShutdownSkipped when next shutdown is skipped
+	ShutdownSkipped EventType = "skipped"
+	// ShutdownPaused when shutdowns are paused
+	ShutdownPaused EventType = "paused"
+	// ShutdownResumed when shutdowns are resumed
+	ShutdownResumed EventType = "resumed"
+	// ShutdownStarted when shutdown job is triggered, also emitted in dry run
+	ShutdownStarted EventType = "shutdown_started"
+	// ShutdownFailed when shutdown task returned error
+	ShutdownFailed EventType = "shutdown_failed"
+)
+
+// Event of the shutdown lifecycle
+type Event struct {
+	Type         EventType
+	Time         time.Time
+	ShutdownTime time.Time
+	DryRun       bool
+	SnoozeCount  int
+	// Reason of triggered shutdown, e.g. low battery or overheated, empty for scheduled shutdown
+	Reason string
+	Source ShutdownSource
+	Err    error
+}
+
+// Subscribe to events of the scheduler, events are dropped if the channel is not drained.
+// The channel is closed on Close, it is returned closed after Close
+func (s *Scheduler) Subscribe() <-chan Event {
+	c := make(chan Event, 16)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		close(c)
+		return c
+	}
+	s.subscribers = append(s.subscribers, c)
+	return c
+}
+
+// Unsubscribe channel returned by Subscribe
+func (s *Scheduler) Unsubscribe(c <-chan Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, sub := range s.subscribers {
+		if sub == c {
+			s.subscribers = append(s.subscribers[:i], s.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// emit event to subscribers and webhooks, the returned channel is closed once webhooks are delivered
+func (s *Scheduler) emit(e Event) <-chan struct{} {
+	e.Time = s.now()
+	e.DryRun = s.config.DryRun
+	e.SnoozeCount = s.snoozeCount
+	if e.Source == "" {
+		e.Reason = s.request.Reason
+		e.Source = s.request.Source
+	}
+	s.record(e)
+	for _, c := range s.subscribers {
+		select {
+		case c <- e:
+		default:
+			// in case no one is draining the channel
+		}
+	}
+	return s.deliverWebhooks(e)
+}
@@ -0,0 +1,23 @@
+package shutd
+
+// historySize of shutdowns kept by Scheduler.History
+const historySize = 10
+
+// History of recent ShutdownStarted and ShutdownFailed events since the scheduler is created, oldest first.
+// It shows why dry run or failed shutdowns happened, e.g. by thermal with the hottest sensor as reason
+func (s *Scheduler) History() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.history...)
+}
+
+// record shutdown event in history, other events are ignored
+func (s *Scheduler) record(e Event) {
+	if e.Type != ShutdownStarted && e.Type != ShutdownFailed {
+		return
+	}
+	s.history = append(s.history, e)
+	if len(s.history) > historySize {
+		s.history = s.history[len(s.history)-historySize:]
+	}
+}
@@ -0,0 +1,27 @@
+package shutd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryKeepsRecentShutdowns(t *testing.T) {
+	config := getDefaultConfig()
+	config.DryRun = true
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+	assert.Empty(t, s.History())
+
+	assert.NoError(t, s.Snooze())
+	for i := 0; i < historySize+2; i++ {
+		assert.NoError(t, s.ShutdownNow())
+	}
+	history := s.History()
+	assert.Len(t, history, historySize)
+	for _, e := range history {
+		assert.Equal(t, ShutdownStarted, e.Type)
+		assert.Equal(t, SourceAPI, e.Source)
+		assert.True(t, e.DryRun)
+	}
+}
@@ -0,0 +1,76 @@
+package shutd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// preShutdownTimeout bounds hooks run before the shutdown task, so unreachable peers do not hold up the shutdown
+const preShutdownTimeout = 15 * time.Second
+
+// PreShutdownHook runs right before the shutdown task, e.g. to shut down peers. It is not run in dry run,
+// ctx is done once the shutdown does not wait for it anymore
+type PreShutdownHook func(ctx context.Context, r ShutdownRequest) error
+
+type preShutdownHook struct {
+	id   int
+	hook PreShutdownHook
+}
+
+// AddPreShutdownHook to run before every shutdown, the returned function removes the hook
+func (s *Scheduler) AddPreShutdownHook(h PreShutdownHook) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastHookID++
+	id := s.lastHookID
+	s.preShutdownHooks = append(s.preShutdownHooks, preShutdownHook{id: id, hook: h})
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, h := range s.preShutdownHooks {
+			if h.id == id {
+				s.preShutdownHooks = append(s.preShutdownHooks[:i], s.preShutdownHooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// runPreShutdownHooks in parallel, the returned channel is closed once they are done or preShutdownTimeout passes
+func (s *Scheduler) runPreShutdownHooks(log *logrus.Entry, hooks []preShutdownHook, r ShutdownRequest) <-chan struct{} {
+	done := make(chan struct{})
+	if len(hooks) == 0 {
+		close(done)
+		return done
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, preShutdownTimeout)
+	var wg sync.WaitGroup
+	for _, h := range hooks {
+		wg.Add(1)
+		go func(h PreShutdownHook) {
+			defer wg.Done()
+			err := h(ctx, r)
+			if err != nil {
+				log.WithError(err).Error("failed to run pre-shutdown hook")
+			}
+		}(h.hook)
+	}
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	go func() {
+		defer close(done)
+		defer cancel()
+		select {
+		case <-finished:
+		case <-ctx.Done():
+			log.Warn("pre-shutdown hooks are not done before shutdown")
+		}
+	}()
+	return done
+}
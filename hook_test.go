@@ -0,0 +1,42 @@
+package shutd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreShutdownHookRunsBeforeShutdownTask(t *testing.T) {
+	var order []string
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		order = append(order, "task")
+		return nil
+	}
+	s, err := getSchedulerWithConfig(t, getDefaultConfig(), WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+	remove := s.AddPreShutdownHook(func(ctx context.Context, r ShutdownRequest) error {
+		assert.Equal(t, SourceAPI, r.Source)
+		order = append(order, "hook")
+		return nil
+	})
+
+	assert.NoError(t, s.ShutdownNow())
+	assert.Equal(t, []string{"hook", "task"}, order)
+
+	remove()
+	assert.NoError(t, s.ShutdownNow())
+	assert.Equal(t, []string{"hook", "task", "task"}, order)
+}
+
+func TestPreShutdownHookIsNotRunInDryRun(t *testing.T) {
+	config := getDefaultConfig()
+	config.DryRun = true
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+	s.AddPreShutdownHook(func(ctx context.Context, r ShutdownRequest) error {
+		t.Error("hook should not be run in dry run")
+		return nil
+	})
+	assert.NoError(t, s.ShutdownNow())
+}
@@ -0,0 +1,330 @@
+package shutd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOccurrences to look through for recurring event, so broken rules do not loop forever
+const maxOccurrences = 100000
+
+// calendarEvent of iCalendar VEVENT, only properties used for exclusions are parsed
+type calendarEvent struct {
+	Summary string
+	Start   time.Time
+	End     time.Time
+	// AllDay event starts and ends at local midnight, end is exclusive
+	AllDay  bool
+	Rule    *recurrenceRule
+	ExDates []time.Time
+}
+
+// recurrenceRule of RRULE basics, BYDAY is only supported for weekly rule
+type recurrenceRule struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    time.Time
+	ByDay    []time.Weekday
+}
+
+// parseICS parses events of iCalendar, see RFC 5545
+func parseICS(r io.Reader) ([]calendarEvent, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read iCalendar: %w", err)
+	}
+	var events []calendarEvent
+	var e *calendarEvent
+	var duration time.Duration
+	for i, line := range lines {
+		name, params, value := parseICSProperty(line)
+		if name == "BEGIN" && value == "VEVENT" {
+			e = &calendarEvent{}
+			duration = 0
+			continue
+		}
+		if e == nil {
+			continue
+		}
+		switch name {
+		case "END":
+			if value != "VEVENT" {
+				continue
+			}
+			if e.Start.IsZero() {
+				return nil, fmt.Errorf("event %q without DTSTART", e.Summary)
+			}
+			if e.End.IsZero() {
+				e.End = e.Start.Add(duration)
+				if e.AllDay && duration == 0 {
+					e.End = e.Start.AddDate(0, 0, 1)
+				}
+			}
+			events = append(events, *e)
+			e = nil
+		case "SUMMARY":
+			e.Summary = unescapeICSText(value)
+		case "DTSTART":
+			e.Start, e.AllDay, err = parseICSTime(value, params)
+		case "DTEND":
+			e.End, _, err = parseICSTime(value, params)
+		case "DURATION":
+			duration, err = parseICSDuration(value)
+		case "RRULE":
+			e.Rule, err = parseRecurrenceRule(value)
+		case "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				var t time.Time
+				t, _, err = parseICSTime(v, params)
+				if err != nil {
+					break
+				}
+				e.ExDates = append(e.ExDates, t)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v of event at line %v: %v", name, i+1, err)
+		}
+	}
+	return events, nil
+}
+
+// unfoldICS joins long lines folded with leading space or tab
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICSProperty of "NAME;PARAM=VALUE:value", colon in quoted param value is not the separator
+func parseICSProperty(line string) (string, map[string]string, string) {
+	quoted := false
+	sep := -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return "", nil, ""
+	}
+	parts := strings.Split(line[:sep], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) == 2 {
+			params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[sep+1:]
+}
+
+func unescapeICSText(v string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(v)
+}
+
+// parseICSTime parses DATE or DATE-TIME in UTC, TZID or local time, date is returned as local midnight.
+// Time is kept in its time zone, so recurrences follow daylight saving time of it
+func parseICSTime(v string, params map[string]string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(v) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", v, time.Local)
+		return t, true, err
+	}
+	if strings.HasSuffix(v, "Z") {
+		t, err := time.Parse("20060102T150405Z", v)
+		return t, false, err
+	}
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		l, err := time.LoadLocation(tzid)
+		if err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", v, loc)
+	return t, false, err
+}
+
+// parseICSDuration parses duration like "PT1H30M" or "P1D", weeks and days are 24 hours
+func parseICSDuration(v string) (time.Duration, error) {
+	s := strings.TrimPrefix(strings.TrimPrefix(v, "+"), "P")
+	if s == strings.TrimPrefix(v, "+") {
+		return 0, fmt.Errorf("invalid duration %q", v)
+	}
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour, 'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+	var d time.Duration
+	n := ""
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			n += string(c)
+		case c == 'T':
+		case units[c] > 0 && n != "":
+			count, _ := strconv.Atoi(n)
+			d += time.Duration(count) * units[c]
+			n = ""
+		default:
+			return 0, fmt.Errorf("invalid duration %q", v)
+		}
+	}
+	return d, nil
+}
+
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+func parseRecurrenceRule(v string) (*recurrenceRule, error) {
+	rule := &recurrenceRule{Interval: 1}
+	for _, part := range strings.Split(v, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		var err error
+		key, value := strings.ToUpper(kv[0]), kv[1]
+		switch key {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(value)
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err == nil && rule.Interval <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(value)
+		case "UNTIL":
+			rule.Until, _, err = parseICSTime(value, nil)
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := icsWeekdays[strings.ToUpper(day)]
+				if !ok {
+					return nil, fmt.Errorf("unsupported BYDAY %q", day)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "WKST":
+		default:
+			return nil, fmt.Errorf("unsupported rule part %v", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v %q: %v", key, value, err)
+		}
+	}
+	switch rule.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return nil, fmt.Errorf("unsupported FREQ %q", rule.Freq)
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != "WEEKLY" {
+		return nil, fmt.Errorf("BYDAY is only supported for WEEKLY")
+	}
+	sort.Slice(rule.ByDay, func(i, j int) bool {
+		return weekdayFromMonday(rule.ByDay[i]) < weekdayFromMonday(rule.ByDay[j])
+	})
+	return rule, nil
+}
+
+func weekdayFromMonday(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
+
+// covering returns end of the occurrence which covers t, occurrences start inclusive and end exclusive
+func (e calendarEvent) covering(t time.Time) (time.Time, bool) {
+	var end time.Time
+	covered := false
+	e.occurrences(t, func(start time.Time) {
+		if e.excluded(start) {
+			return
+		}
+		if occurrenceEnd := e.endOf(start); t.Before(occurrenceEnd) && !t.Before(start) {
+			end = occurrenceEnd
+			covered = true
+		}
+	})
+	return end, covered
+}
+
+// endOf occurrence starting at start, all-day event lasts by days so it ends at midnight across DST change
+func (e calendarEvent) endOf(start time.Time) time.Time {
+	if e.AllDay {
+		days := int(math.Round(e.End.Sub(e.Start).Hours() / 24))
+		return start.AddDate(0, 0, days)
+	}
+	return start.Add(e.End.Sub(e.Start))
+}
+
+func (e calendarEvent) excluded(start time.Time) bool {
+	for _, ex := range e.ExDates {
+		if ex.Equal(start) || (e.AllDay && ex.Format("20060102") == start.Format("20060102")) {
+			return true
+		}
+	}
+	return false
+}
+
+// occurrences calls f with start of each occurrence until after t
+func (e calendarEvent) occurrences(t time.Time, f func(start time.Time)) {
+	if e.Rule == nil {
+		f(e.Start)
+		return
+	}
+	r := e.Rule
+	count := 0
+	for k := 0; k < maxOccurrences; k++ {
+		var starts []time.Time
+		switch r.Freq {
+		case "DAILY":
+			starts = []time.Time{e.Start.AddDate(0, 0, k*r.Interval)}
+		case "WEEKLY":
+			if len(r.ByDay) == 0 {
+				starts = []time.Time{e.Start.AddDate(0, 0, 7*k*r.Interval)}
+				break
+			}
+			monday := e.Start.AddDate(0, 0, 7*k*r.Interval-weekdayFromMonday(e.Start.Weekday()))
+			for _, d := range r.ByDay {
+				start := monday.AddDate(0, 0, weekdayFromMonday(d))
+				if !start.Before(e.Start) {
+					starts = append(starts, start)
+				}
+			}
+		case "MONTHLY":
+			// months without the day are skipped
+			if start := e.Start.AddDate(0, k*r.Interval, 0); start.Day() == e.Start.Day() {
+				starts = []time.Time{start}
+			}
+		case "YEARLY":
+			if start := e.Start.AddDate(k*r.Interval, 0, 0); start.Day() == e.Start.Day() {
+				starts = []time.Time{start}
+			}
+		}
+		for _, start := range starts {
+			if start.After(t) || (!r.Until.IsZero() && start.After(r.Until)) || (r.Count > 0 && count >= r.Count) {
+				return
+			}
+			count++
+			f(start)
+		}
+	}
+}
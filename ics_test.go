@@ -0,0 +1,141 @@
+package shutd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Christmas\r\n" +
+	"DTSTART;VALUE=DATE:20201225\r\n" +
+	"RRULE:FREQ=YEARLY\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Release night\\, team\r\n" +
+	" A\r\n" +
+	"DTSTART;TZID=\"Europe/London\":20261016T200000\r\n" +
+	"DURATION:PT6H30M\r\n" +
+	"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=FR,TH;COUNT=4\r\n" +
+	"EXDATE;TZID=Europe/London:20261029T200000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Vacation\r\n" +
+	"DTSTART;VALUE=DATE:20261102\r\n" +
+	"DTEND;VALUE=DATE:20261105\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICS(t *testing.T) {
+	events, err := parseICS(strings.NewReader(testICS))
+	assert.NoError(t, err)
+	if !assert.Len(t, events, 3) {
+		return
+	}
+
+	assert.Equal(t, "Christmas", events[0].Summary)
+	assert.True(t, events[0].AllDay)
+	assert.Equal(t, time.Date(2020, 12, 25, 0, 0, 0, 0, time.Local), events[0].Start)
+	assert.Equal(t, time.Date(2020, 12, 26, 0, 0, 0, 0, time.Local), events[0].End)
+	assert.Equal(t, &recurrenceRule{Freq: "YEARLY", Interval: 1}, events[0].Rule)
+
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+	assert.Equal(t, "Release night, teamA", events[1].Summary)
+	assert.False(t, events[1].AllDay)
+	assert.True(t, time.Date(2026, 10, 16, 20, 0, 0, 0, london).Equal(events[1].Start))
+	assert.Equal(t, 6*time.Hour+30*time.Minute, events[1].End.Sub(events[1].Start))
+	assert.Equal(t, &recurrenceRule{Freq: "WEEKLY", Interval: 2, Count: 4, ByDay: []time.Weekday{time.Thursday, time.Friday}}, events[1].Rule)
+	assert.Len(t, events[1].ExDates, 1)
+
+	assert.Equal(t, time.Date(2026, 11, 5, 0, 0, 0, 0, time.Local), events[2].End)
+}
+
+func TestCalendarEventCovering(t *testing.T) {
+	events, err := parseICS(strings.NewReader(testICS))
+	assert.NoError(t, err)
+	christmas, release, vacation := events[0], events[1], events[2]
+
+	_, ok := christmas.covering(time.Date(2026, 12, 25, 0, 0, 0, 0, time.Local))
+	assert.True(t, ok)
+	_, ok = christmas.covering(time.Date(2026, 12, 24, 0, 0, 0, 0, time.Local))
+	assert.False(t, ok)
+	_, ok = christmas.covering(time.Date(2019, 12, 25, 0, 0, 0, 0, time.Local))
+	assert.False(t, ok)
+
+	_, ok = vacation.covering(time.Date(2026, 11, 4, 0, 0, 0, 0, time.Local))
+	assert.True(t, ok)
+	_, ok = vacation.covering(time.Date(2026, 11, 5, 0, 0, 0, 0, time.Local))
+	assert.False(t, ok)
+
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+	// occurrences on Fri 16, Thu 29 (excluded), Fri 30 of October, and Thu 12 of November
+	for _, c := range []struct {
+		t       time.Time
+		covered bool
+	}{
+		{time.Date(2026, 10, 17, 1, 0, 0, 0, london), true},
+		{time.Date(2026, 10, 22, 21, 0, 0, 0, london), false},
+		{time.Date(2026, 10, 29, 21, 0, 0, 0, london), false},
+		{time.Date(2026, 10, 30, 21, 0, 0, 0, london), true},
+		{time.Date(2026, 11, 12, 21, 0, 0, 0, london), true},
+		{time.Date(2026, 11, 13, 21, 0, 0, 0, london), false},
+	} {
+		end, ok := release.covering(c.t)
+		assert.Equal(t, c.covered, ok, "%v", c.t)
+		if ok {
+			assert.True(t, end.After(c.t))
+		}
+	}
+	end, _ := release.covering(time.Date(2026, 10, 17, 1, 0, 0, 0, london))
+	assert.True(t, time.Date(2026, 10, 17, 2, 30, 0, 0, london).Equal(end))
+}
+
+func TestRecurrenceRuleUntil(t *testing.T) {
+	ics := "BEGIN:VEVENT\nSUMMARY:Standup\nDTSTART:20261019T090000Z\nDTEND:20261019T091500Z\nRRULE:FREQ=DAILY;UNTIL=20261021T090000Z\nEND:VEVENT\n"
+	events, err := parseICS(strings.NewReader(ics))
+	assert.NoError(t, err)
+
+	_, ok := events[0].covering(time.Date(2026, 10, 21, 9, 5, 0, 0, time.UTC))
+	assert.True(t, ok)
+	_, ok = events[0].covering(time.Date(2026, 10, 22, 9, 5, 0, 0, time.UTC))
+	assert.False(t, ok)
+}
+
+func TestParseICSWithInvalidEvent(t *testing.T) {
+	_, err := parseICS(strings.NewReader("BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\n"))
+	assert.EqualError(t, err, `event "x" without DTSTART`)
+
+	_, err = parseICS(strings.NewReader("BEGIN:VEVENT\nDTSTART:20261019\nRRULE:FREQ=MONTHLY;BYDAY=2MO\nEND:VEVENT\n"))
+	assert.EqualError(t, err, "invalid RRULE of event at line 3: unsupported BYDAY \"2MO\"")
+
+	_, err = parseICS(strings.NewReader("BEGIN:VEVENT\nDTSTART:20261019\nRRULE:FREQ=MONTHLY;BYSETPOS=1\nEND:VEVENT\n"))
+	assert.EqualError(t, err, "invalid RRULE of event at line 3: unsupported rule part BYSETPOS")
+
+	_, err = parseICS(strings.NewReader("BEGIN:VEVENT\nDTSTART:2026-10-19\nEND:VEVENT\n"))
+	assert.Error(t, err)
+}
+
+func TestParseICSDuration(t *testing.T) {
+	for v, expected := range map[string]time.Duration{
+		"PT1H30M":  90 * time.Minute,
+		"P1D":      24 * time.Hour,
+		"P1W":      7 * 24 * time.Hour,
+		"P1DT2H3S": 26*time.Hour + 3*time.Second,
+	} {
+		d, err := parseICSDuration(v)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, d, v)
+	}
+	_, err := parseICSDuration("1H")
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package shutd
+
+import (
+	"sync"
+	"time"
+)
+
+// dailyJob runs daily at the clock time of its next run, the next run is tracked by the job itself,
+// so it is rescheduled without waiting for the running job, unlike gocron jobs
+type dailyJob struct {
+	tag string
+	run func()
+
+	// mu guards the fields below, it is not held while running the job
+	mu    sync.Mutex
+	next  time.Time
+	timer *time.Timer
+	// gen is bumped on every schedule, so the timer replaced meanwhile does not run the job
+	gen int
+}
+
+func newDailyJob(tag string, run func()) *dailyJob {
+	return &dailyJob{tag: tag, run: run}
+}
+
+// ScheduledTime of the next run
+func (j *dailyJob) ScheduledTime() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.next
+}
+
+// Tags of the job, the same as gocron jobs
+func (j *dailyJob) Tags() []string {
+	return []string{j.tag}
+}
+
+// schedule the next run, the job is only run by timer when armed, otherwise it has to be run by the caller.
+// Next run in the past is run immediately
+func (j *dailyJob) schedule(next time.Time, armed bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.arm(next, armed)
+}
+
+// stop the job, it is not run until scheduled again
+func (j *dailyJob) stop() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.arm(j.next, false)
+}
+
+// arm requires j.mu to be held
+func (j *dailyJob) arm(next time.Time, armed bool) {
+	if j.timer != nil {
+		j.timer.Stop()
+		j.timer = nil
+	}
+	j.gen++
+	j.next = next
+	if !armed {
+		return
+	}
+	gen := j.gen
+	j.timer = time.AfterFunc(time.Until(next), func() {
+		j.fire(gen)
+	})
+}
+
+// fire the run of gen, the next run a day later is scheduled before running the job, so the job can reschedule itself
+func (j *dailyJob) fire(gen int) {
+	j.mu.Lock()
+	if gen != j.gen {
+		j.mu.Unlock()
+		return
+	}
+	j.arm(nextClockTime(j.next, sinceMidnight(j.next)+time.Duration(j.next.Nanosecond())), true)
+	j.mu.Unlock()
+	j.run()
+}
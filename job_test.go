@@ -0,0 +1,41 @@
+package shutd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyJobRunsOnlyLatestSchedule(t *testing.T) {
+	runs := make(chan time.Time, 2)
+	j := newDailyJob("test", func() { runs <- time.Now() })
+	defer j.stop()
+
+	j.schedule(time.Now().Add(100*time.Millisecond), true)
+	next := time.Now().Add(300 * time.Millisecond)
+	j.schedule(next, true)
+
+	select {
+	case ranAt := <-runs:
+		assert.False(t, ranAt.Before(next), "job should run at the latest schedule")
+	case <-time.After(2 * time.Second):
+		t.Fatal("job should run")
+	}
+	assert.Equal(t, next.AddDate(0, 0, 1).Format(time.RFC3339), j.ScheduledTime().Format(time.RFC3339))
+	select {
+	case <-runs:
+		t.Fatal("replaced schedule should not run")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestDailyJobNotRunUnlessArmed(t *testing.T) {
+	j := newDailyJob("test", func() { t.Error("job should not run") })
+	j.schedule(time.Now().Add(-time.Minute), false)
+	time.Sleep(100 * time.Millisecond)
+
+	j.schedule(time.Now().Add(50*time.Millisecond), true)
+	j.stop()
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,44 @@
+package shutd
+
+import "time"
+
+// scheduleMonitorJob polling check every interval, the job is removed when monitor is not enabled
+func (s *Scheduler) scheduleMonitorJob(tag string, enabled bool, interval time.Duration, check func()) error {
+	s.scheduler.RemoveByTag(tag)
+	if !enabled {
+		return nil
+	}
+	_, err := s.scheduler.Every(interval).Tag(tag).Do(check)
+	if err != nil {
+		return &ScheduleError{Job: tag, Cause: err.Error()}
+	}
+	return nil
+}
+
+// trigger shutdown request at its deadline regardless of start time, snooze notification is shown immediately
+func (s *Scheduler) trigger(r ShutdownRequest) error {
+	if s.shutdownJob == nil {
+		return ErrNotScheduled
+	}
+	r.Deadline = laterTime(r.Deadline, s.now().Add(minTriggerDelay))
+	if s.scheduledTime().Before(r.Deadline) {
+		// already shutting down sooner, while it could not be snoozed anymore
+		if s.request.Source == SourceScheduled {
+			s.request = r
+		}
+		s.request.Snoozable = s.request.Snoozable && r.Snoozable
+		return nil
+	}
+	r.Snoozable = r.Snoozable && s.request.Snoozable
+	s.request = r
+	err := s.scheduleShutdownJob(r.Deadline)
+	if err != nil {
+		return err
+	}
+	err = s.scheduleSnoozeNotificationJob()
+	if err != nil {
+		return err
+	}
+	s.printJobs()
+	return nil
+}
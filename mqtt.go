@@ -0,0 +1,300 @@
+package shutd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	defaultDiscoveryPrefix = "homeassistant"
+	mqttTimeout            = 10 * time.Second
+	defaultMQTTRetry       = 10 * time.Second
+)
+
+// MQTTConfig for publishing state and receiving commands via MQTT broker
+type MQTTConfig struct {
+	// Broker URL, e.g. tcp://localhost:1883, empty to disable MQTT
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+	// TopicPrefix of state and command topics, defaults to shutd/<hostname>
+	TopicPrefix string
+	// DiscoveryPrefix of Home Assistant discovery topics, defaults to homeassistant
+	DiscoveryPrefix string
+	// DisableDiscovery to not publish Home Assistant discovery messages
+	DisableDiscovery bool
+	// RetryInterval of connecting while the broker is unavailable, defaults to 10s
+	RetryInterval time.Duration
+}
+
+// MQTTState published to state topic
+type MQTTState struct {
+	NextShutdown time.Time `json:"nextShutdown"`
+	Paused       bool      `json:"paused"`
+	Skipped      bool      `json:"skipped"`
+	SnoozeCount  int       `json:"snoozeCount"`
+	DryRun       bool      `json:"dryRun"`
+}
+
+// MQTTBridge publishes scheduler state to MQTT broker and handles snooze, skip, pause and resume commands
+type MQTTBridge struct {
+	scheduler *Scheduler
+	config    MQTTConfig
+	node      string
+	client    mqtt.Client
+	events    <-chan Event
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+var invalidNodeChars = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// NewMQTTBridge connects to the broker and starts publishing state of the scheduler. Connecting is retried
+// in background while the broker is unavailable, also after the connection is lost
+func NewMQTTBridge(s *Scheduler, config MQTTConfig) (*MQTTBridge, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+	node := invalidNodeChars.ReplaceAllString(strings.ToLower(host), "_")
+	if config.TopicPrefix == "" {
+		config.TopicPrefix = "shutd/" + node
+	}
+	if config.DiscoveryPrefix == "" {
+		config.DiscoveryPrefix = defaultDiscoveryPrefix
+	}
+	if config.ClientID == "" {
+		config.ClientID = "shutd_" + node
+	}
+	retry := config.RetryInterval
+	if retry <= 0 {
+		retry = defaultMQTTRetry
+	}
+
+	b := &MQTTBridge{
+		scheduler: s,
+		config:    config,
+		node:      node,
+		done:      make(chan struct{}),
+	}
+	opts := mqtt.NewClientOptions().
+		AddBroker(config.Broker).
+		SetClientID(config.ClientID).
+		SetUsername(config.Username).
+		SetPassword(config.Password).
+		SetWill(b.availabilityTopic(), "offline", 1, true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(retry).
+		SetAutoReconnect(true).
+		SetMaxReconnectInterval(retry).
+		SetOnConnectHandler(b.onConnect).
+		SetConnectionLostHandler(func(c mqtt.Client, err error) {
+			s.Logger().WithError(err).Warn("lost connection to MQTT broker: reconnecting")
+		})
+	b.client = mqtt.NewClient(opts)
+	token := b.client.Connect()
+
+	b.events = s.Subscribe()
+	go b.run()
+	go func() {
+		select {
+		case <-token.Done():
+			// token is only done without connection once closed
+			if err := token.Error(); err != nil && !b.closed() {
+				s.Logger().WithError(err).Error("failed to connect MQTT broker")
+			}
+		case <-time.After(mqttTimeout):
+			s.Logger().WithField("broker", config.Broker).Warn("MQTT broker is unavailable: retrying to connect")
+		case <-b.done:
+		}
+	}()
+	return b, nil
+}
+
+// Close publishes offline availability and disconnects from broker
+func (b *MQTTBridge) Close() {
+	b.closeOnce.Do(func() {
+		close(b.done)
+		b.scheduler.Unsubscribe(b.events)
+		b.publish(b.availabilityTopic(), "offline", true)
+		b.client.Disconnect(250)
+	})
+}
+
+func (b *MQTTBridge) closed() bool {
+	select {
+	case <-b.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *MQTTBridge) stateTopic() string {
+	return b.config.TopicPrefix + "/state"
+}
+
+func (b *MQTTBridge) commandTopic() string {
+	return b.config.TopicPrefix + "/command"
+}
+
+func (b *MQTTBridge) availabilityTopic() string {
+	return b.config.TopicPrefix + "/availability"
+}
+
+func (b *MQTTBridge) run() {
+	for {
+		select {
+		case _, ok := <-b.events:
+			if !ok {
+				// scheduler is closed
+				return
+			}
+			b.publishState()
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// onConnect is called on every connect, so state is restored after reconnect
+func (b *MQTTBridge) onConnect(c mqtt.Client) {
+	token := c.Subscribe(b.commandTopic(), 1, func(c mqtt.Client, m mqtt.Message) {
+		b.handleCommand(string(m.Payload()))
+	})
+	if token.WaitTimeout(mqttTimeout) && token.Error() != nil {
+		b.scheduler.Logger().WithError(token.Error()).Error("failed to subscribe MQTT command topic")
+	}
+	if !b.config.DisableDiscovery {
+		b.publishDiscovery()
+	}
+	b.publish(b.availabilityTopic(), "online", true)
+	b.publishState()
+}
+
+func (b *MQTTBridge) handleCommand(command string) {
+	s := b.scheduler
+	// PIN follows the command when protected, e.g. "snooze 1234". Resume takes no PIN, as it does not delay shutdown
+	fields := strings.Fields(command)
+	name, pin := "", ""
+	if len(fields) > 0 {
+		name = strings.ToLower(fields[0])
+	}
+	if len(fields) > 1 {
+		pin = fields[1]
+	}
+	var err error
+	switch name {
+	case "snooze":
+		err = s.SnoozeWithPIN(pin)
+	case "skip":
+		err = s.SkipWithPIN(pin)
+	case "pause":
+		err = s.PauseWithPIN(pin)
+	case "resume":
+		err = s.Resume()
+	default:
+		err = fmt.Errorf("unknown command: %v", name)
+	}
+	if err != nil {
+		s.Logger().WithError(err).WithField("command", name).Error("failed to handle MQTT command")
+	}
+}
+
+func (b *MQTTBridge) state() MQTTState {
+	s := b.scheduler
+	state := MQTTState{
+		Paused:      s.Paused(),
+		Skipped:     s.Skipped(),
+		SnoozeCount: s.SnoozeCount(),
+		DryRun:      s.Config().DryRun,
+	}
+	if t, err := s.ShutdownTime(); err == nil {
+		state.NextShutdown = t
+	}
+	return state
+}
+
+func (b *MQTTBridge) publishState() {
+	payload, err := json.Marshal(b.state())
+	if err != nil {
+		b.scheduler.Logger().WithError(err).Error("failed to encode MQTT state")
+		return
+	}
+	b.publish(b.stateTopic(), string(payload), true)
+}
+
+// publishDiscovery of Home Assistant entities, so the machine appears as a device
+func (b *MQTTBridge) publishDiscovery() {
+	host, _ := os.Hostname()
+	device := map[string]interface{}{
+		"identifiers":  []string{"shutd_" + b.node},
+		"name":         "Shutd " + host,
+		"manufacturer": "shutd",
+	}
+	entity := func(component, id, name string, extra map[string]interface{}) {
+		config := map[string]interface{}{
+			"name":               name,
+			"unique_id":          b.node + "_" + id,
+			"availability_topic": b.availabilityTopic(),
+			"device":             device,
+		}
+		for k, v := range extra {
+			config[k] = v
+		}
+		payload, err := json.Marshal(config)
+		if err != nil {
+			b.scheduler.Logger().WithError(err).Error("failed to encode MQTT discovery")
+			return
+		}
+		topic := fmt.Sprintf("%v/%v/%v/%v/config", b.config.DiscoveryPrefix, component, b.node, id)
+		b.publish(topic, string(payload), true)
+	}
+	entity("button", "snooze", "Snooze", map[string]interface{}{
+		"command_topic": b.commandTopic(),
+		"payload_press": "snooze",
+	})
+	entity("button", "skip", "Skip next shutdown", map[string]interface{}{
+		"command_topic": b.commandTopic(),
+		"payload_press": "skip",
+	})
+	entity("switch", "pause", "Pause", map[string]interface{}{
+		"command_topic":  b.commandTopic(),
+		"payload_on":     "pause",
+		"payload_off":    "resume",
+		"state_topic":    b.stateTopic(),
+		"value_template": "{{ 'pause' if value_json.paused else 'resume' }}",
+	})
+	entity("sensor", "next_shutdown", "Next shutdown", map[string]interface{}{
+		"device_class":   "timestamp",
+		"state_topic":    b.stateTopic(),
+		"value_template": "{{ value_json.nextShutdown }}",
+	})
+	entity("sensor", "snooze_count", "Snooze count", map[string]interface{}{
+		"state_topic":    b.stateTopic(),
+		"value_template": "{{ value_json.snoozeCount }}",
+	})
+}
+
+// publish once connected, state and discovery are published again on connect
+func (b *MQTTBridge) publish(topic, payload string, retained bool) {
+	if !b.client.IsConnectionOpen() {
+		return
+	}
+	token := b.client.Publish(topic, 1, retained, payload)
+	if !token.WaitTimeout(mqttTimeout) {
+		b.scheduler.Logger().WithField("topic", topic).Error("failed to publish MQTT message: timeout")
+		return
+	}
+	if err := token.Error(); err != nil {
+		b.scheduler.Logger().WithError(err).WithField("topic", topic).Error("failed to publish MQTT message")
+	}
+}
@@ -0,0 +1,146 @@
+package shutd
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	mqttserver "github.com/mochi-co/mqtt/server"
+	"github.com/mochi-co/mqtt/server/listeners"
+	"github.com/stretchr/testify/assert"
+)
+
+func startMQTTBroker(t *testing.T) string {
+	return startMQTTBrokerAt(t, freeAddr(t))
+}
+
+// freeAddr to listen on later, e.g. for broker started after the bridge
+func freeAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func startMQTTBrokerAt(t *testing.T, addr string) string {
+	server := mqttserver.New()
+	err := server.AddListener(listeners.NewTCP("t1", addr), nil)
+	assert.NoError(t, err)
+	err = server.Serve()
+	assert.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+	return "tcp://" + addr
+}
+
+type mqttMessages struct {
+	sync.Mutex
+	messages map[string]string
+}
+
+func (m *mqttMessages) get(topic string) (string, bool) {
+	m.Lock()
+	defer m.Unlock()
+	v, ok := m.messages[topic]
+	return v, ok
+}
+
+func subscribeMQTT(t *testing.T, broker string, topic string) (mqtt.Client, *mqttMessages) {
+	messages := &mqttMessages{messages: map[string]string{}}
+	client := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(broker).SetClientID("test"))
+	token := client.Connect()
+	assert.True(t, token.WaitTimeout(2*time.Second))
+	assert.NoError(t, token.Error())
+	token = client.Subscribe(topic, 1, func(c mqtt.Client, m mqtt.Message) {
+		messages.Lock()
+		messages.messages[m.Topic()] = string(m.Payload())
+		messages.Unlock()
+	})
+	assert.True(t, token.WaitTimeout(2*time.Second))
+	t.Cleanup(func() { client.Disconnect(0) })
+	return client, messages
+}
+
+func waitMQTT(t *testing.T, messages *mqttMessages, topic string, cond func(string) bool) string {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if v, ok := messages.get(topic); ok && cond(v) {
+			return v
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	v, _ := messages.get(topic)
+	t.Fatalf("unexpected message on %v: %v", topic, v)
+	return ""
+}
+
+func TestMQTTBridge(t *testing.T) {
+	broker := startMQTTBroker(t)
+	client, messages := subscribeMQTT(t, broker, "#")
+
+	s := getScheduler(t)
+	b, err := NewMQTTBridge(s, MQTTConfig{Broker: broker, TopicPrefix: "shutd/test"})
+	assert.NoError(t, err)
+	defer b.Close()
+
+	waitMQTT(t, messages, "shutd/test/availability", func(v string) bool { return v == "online" })
+	state := waitMQTT(t, messages, "shutd/test/state", func(v string) bool { return v != "" })
+	var s1 MQTTState
+	assert.NoError(t, json.Unmarshal([]byte(state), &s1))
+	assert.Equal(t, "00:00", s1.NextShutdown.Format("15:04"))
+
+	discovery := waitMQTT(t, messages, "homeassistant/button/"+b.node+"/snooze/config", func(v string) bool { return v != "" })
+	assert.Contains(t, discovery, `"command_topic":"shutd/test/command"`)
+	assert.Contains(t, discovery, `"payload_press":"snooze"`)
+	waitMQTT(t, messages, "homeassistant/sensor/"+b.node+"/next_shutdown/config", func(v string) bool {
+		return strings.Contains(v, `"device_class":"timestamp"`)
+	})
+
+	client.Publish("shutd/test/command", 1, false, "snooze").WaitTimeout(2 * time.Second)
+	waitMQTT(t, messages, "shutd/test/state", func(v string) bool { return strings.Contains(v, `"snoozeCount":1`) })
+	assert.Equal(t, "00:15", s.shutdownJob.ScheduledTime().Format("15:04"))
+
+	client.Publish("shutd/test/command", 1, false, "pause").WaitTimeout(2 * time.Second)
+	waitMQTT(t, messages, "shutd/test/state", func(v string) bool { return strings.Contains(v, `"paused":true`) })
+	assert.True(t, s.Paused())
+
+	client.Publish("shutd/test/command", 1, false, "skip").WaitTimeout(2 * time.Second)
+	waitMQTT(t, messages, "shutd/test/state", func(v string) bool { return strings.Contains(v, `"skipped":true`) })
+
+	b.Close()
+	waitMQTT(t, messages, "shutd/test/availability", func(v string) bool { return v == "offline" })
+}
+
+func TestMQTTBridgeConnectsOnceBrokerIsAvailable(t *testing.T) {
+	addr := freeAddr(t)
+	s := getScheduler(t)
+	b, err := NewMQTTBridge(s, MQTTConfig{Broker: "tcp://" + addr, TopicPrefix: "shutd/test", RetryInterval: 50 * time.Millisecond})
+	assert.NoError(t, err)
+	defer b.Close()
+
+	// state changes while the broker is down do not block
+	assert.NoError(t, s.Snooze())
+
+	broker := startMQTTBrokerAt(t, addr)
+	_, messages := subscribeMQTT(t, broker, "#")
+	waitMQTT(t, messages, "shutd/test/availability", func(v string) bool { return v == "online" })
+	waitMQTT(t, messages, "shutd/test/state", func(v string) bool { return strings.Contains(v, `"snoozeCount":1`) })
+}
+
+func TestMQTTCommandWithPIN(t *testing.T) {
+	s, err := getSchedulerWithConfig(t, getProtectedConfig(t, "1234"))
+	assert.NoError(t, err)
+	b := &MQTTBridge{scheduler: s}
+
+	b.handleCommand("snooze")
+	assert.Equal(t, 0, s.SnoozeCount())
+	b.handleCommand("snooze 0000")
+	assert.Equal(t, 0, s.SnoozeCount())
+	b.handleCommand("Snooze 1234")
+	assert.Equal(t, 1, s.SnoozeCount())
+	b.handleCommand("skip 1234")
+	assert.True(t, s.Skipped())
+}
@@ -0,0 +1,129 @@
+package shutd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+const (
+	// DialogNotifier shows popup dialog, it is the default notifier
+	DialogNotifier = "dialog"
+	// WallNotifier broadcasts notification to all terminals via wall, it could not be answered
+	WallNotifier = "wall"
+	// TerminalNotifier asks on standard input and output
+	TerminalNotifier = "terminal"
+)
+
+// Notifier asks user whether to snooze the shutdown, returns when answered or ctx is done
+type Notifier interface {
+	Question(ctx context.Context, title, text string) (bool, error)
+}
+
+// NotifierFunc adapts function as Notifier
+type NotifierFunc func(ctx context.Context, title, text string) (bool, error)
+
+// Question calls f(ctx, title, text)
+func (f NotifierFunc) Question(ctx context.Context, title, text string) (bool, error) {
+	return f(ctx, title, text)
+}
+
+func defaultNotifiers() map[string]Notifier {
+	return map[string]Notifier{
+		DialogNotifier:   dialogNotifier{},
+		WallNotifier:     NotifierFunc(wall),
+		TerminalNotifier: NewLineNotifier(os.Stdin, os.Stdout),
+	}
+}
+
+// notifier by name, notifiers are only registered on construction so no lock is needed
+func (s *Scheduler) notifier(name string) (Notifier, error) {
+	if name == "" {
+		name = DialogNotifier
+	}
+	n, ok := s.notifiers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown notifier: %v", name)
+	}
+	return n, nil
+}
+
+// dialogNotifier asks with popup dialog, and asks for PIN with password dialog
+type dialogNotifier struct{}
+
+func (dialogNotifier) Question(ctx context.Context, title, text string) (bool, error) {
+	return question(ctx, title, text)
+}
+
+func (dialogNotifier) AskPIN(ctx context.Context, title, text string) (string, error) {
+	return askPIN(ctx, title, text)
+}
+
+func wall(ctx context.Context, title, text string) (bool, error) {
+	cmd := exec.CommandContext(ctx, "wall")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("%v\n%v\n", title, text))
+	if err := cmd.Run(); err != nil {
+		return false, fmt.Errorf("failed to broadcast with wall: %w", err)
+	}
+	return false, nil
+}
+
+type lineNotifier struct {
+	in    io.Reader
+	out   io.Writer
+	once  sync.Once
+	lines chan string
+}
+
+// NewLineNotifier creates Notifier that writes question to out and reads y/n answer line from in
+func NewLineNotifier(in io.Reader, out io.Writer) Notifier {
+	return &lineNotifier{in: in, out: out, lines: make(chan string)}
+}
+
+func (n *lineNotifier) Question(ctx context.Context, title, text string) (bool, error) {
+	line, err := n.ask(ctx, fmt.Sprintf("%v\n%v [y/N] ", title, text))
+	if err != nil {
+		return false, err
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes", nil
+}
+
+// AskPIN reads PIN line from in, it is echoed as in is not necessarily a terminal
+func (n *lineNotifier) AskPIN(ctx context.Context, title, text string) (string, error) {
+	line, err := n.ask(ctx, fmt.Sprintf("%v\n%v: ", title, text))
+	return strings.TrimSpace(line), err
+}
+
+// ask writes prompt to out and reads answer line from in
+func (n *lineNotifier) ask(ctx context.Context, prompt string) (string, error) {
+	// single reader for all questions, so unanswered question does not steal later answer
+	n.once.Do(func() {
+		go func() {
+			scanner := bufio.NewScanner(n.in)
+			for scanner.Scan() {
+				n.lines <- scanner.Text()
+			}
+			close(n.lines)
+		}()
+	})
+	_, err := fmt.Fprint(n.out, prompt)
+	if err != nil {
+		return "", err
+	}
+	select {
+	case line, ok := <-n.lines:
+		if !ok {
+			return "", io.EOF
+		}
+		return line, nil
+	case <-ctx.Done():
+		fmt.Fprintln(n.out)
+		return "", ctx.Err()
+	}
+}
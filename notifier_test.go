@@ -0,0 +1,89 @@
+package shutd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineNotifier(t *testing.T) {
+	var out bytes.Buffer
+	n := NewLineNotifier(strings.NewReader("y\nno\n"), &out)
+
+	yes, err := n.Question(context.Background(), "title", "text")
+	assert.NoError(t, err)
+	assert.True(t, yes)
+	assert.Equal(t, "title\ntext [y/N] ", out.String())
+
+	yes, err = n.Question(context.Background(), "title", "text")
+	assert.NoError(t, err)
+	assert.False(t, yes)
+
+	_, err = n.Question(context.Background(), "title", "text")
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestLineNotifierTimeout(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	n := NewLineNotifier(r, io.Discard)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := n.Question(ctx, "title", "text")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// answer after timeout should go to the next question
+	go w.Write([]byte("yes\n"))
+	yes, err := n.Question(context.Background(), "title", "text")
+	assert.NoError(t, err)
+	assert.True(t, yes)
+}
+
+func TestSnoozeNotificationTaskWithNotifier(t *testing.T) {
+	asked := make(chan string, 1)
+	notifier := NotifierFunc(func(ctx context.Context, title, text string) (bool, error) {
+		asked <- title
+		return true, nil
+	})
+	shutdownTime := time.Now().Add(3 * time.Minute).Truncate(time.Minute)
+	config := getConfigWithShutdownTime(shutdownTime.Format("15:04"))
+	config.Notification.Notifier = "test"
+	s, err := getSchedulerWithConfig(t, config, WithNotifier("test", notifier), WithSnoozeNotificationTask(newNotificationSnoozeTask()))
+	assert.NoError(t, err)
+
+	select {
+	case title := <-asked:
+		assert.Equal(t, "Shutd - Shutdown at "+shutdownTime.Format("15:04"), title)
+	case <-time.After(2 * time.Second):
+		t.Fatal("notifier should be asked")
+	}
+	time.Sleep(100 * time.Millisecond)
+	snoozed, err := s.ShutdownTime()
+	assert.NoError(t, err)
+	assert.Equal(t, shutdownTime.Add(15*time.Minute).Format("15:04"), snoozed.Format("15:04"))
+}
+
+func TestUnknownNotifier(t *testing.T) {
+	config := getDefaultConfig()
+	config.Notification.Notifier = "unknown"
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+	err = newNotificationSnoozeTask()(s, s.ShutdownRequest())
+	assert.EqualError(t, err, "unknown notifier: unknown")
+}
+
+func TestLineNotifierAskPIN(t *testing.T) {
+	var out bytes.Buffer
+	n := NewLineNotifier(strings.NewReader(" 1234 \n"), &out)
+
+	pin, err := n.(PINAsker).AskPIN(context.Background(), "title", "Enter PIN")
+	assert.NoError(t, err)
+	assert.Equal(t, "1234", pin)
+	assert.Equal(t, "title\nEnter PIN: ", out.String())
+}
@@ -0,0 +1,106 @@
+package shutd
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultNUTPort = 3493
+	nutTimeout     = 10 * time.Second
+)
+
+// NUTClient of Network UPS Tools upsd server speaking the plain-text protocol
+type NUTClient struct {
+	Addr     string
+	Username string
+	Password string
+}
+
+// Var gets variable of the UPS, e.g. ups.status
+func (c NUTClient) Var(ups, name string) (string, error) {
+	conn, err := net.DialTimeout("tcp", c.Addr, nutTimeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect upsd: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(nutTimeout))
+	r := bufio.NewReader(conn)
+
+	if c.Username != "" {
+		_, err = nutCommand(conn, r, "USERNAME "+nutQuote(c.Username))
+		if err != nil {
+			return "", err
+		}
+		_, err = nutCommand(conn, r, "PASSWORD "+nutQuote(c.Password))
+		if err != nil {
+			return "", err
+		}
+	}
+	resp, err := nutCommand(conn, r, fmt.Sprintf("GET VAR %v %v", ups, name))
+	if err != nil {
+		return "", err
+	}
+	prefix := fmt.Sprintf("VAR %v %v ", ups, name)
+	if !strings.HasPrefix(resp, prefix) {
+		return "", fmt.Errorf("unexpected upsd response: %v", resp)
+	}
+	value, err := nutUnquote(strings.TrimPrefix(resp, prefix))
+	if err != nil {
+		return "", fmt.Errorf("unexpected upsd response: %v", resp)
+	}
+	// best effort, upsd closes the connection anyway
+	nutCommand(conn, r, "LOGOUT")
+	return value, nil
+}
+
+// Status gets ups.status of the UPS as flags, e.g. [OB LB]
+func (c NUTClient) Status(ups string) ([]string, error) {
+	status, err := c.Var(ups, "ups.status")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(status), nil
+}
+
+func nutCommand(conn net.Conn, r *bufio.Reader, command string) (string, error) {
+	_, err := fmt.Fprintf(conn, "%v\n", command)
+	if err != nil {
+		return "", fmt.Errorf("failed to send upsd command: %w", err)
+	}
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read upsd response: %w", err)
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(line, "ERR ") {
+		return "", fmt.Errorf("upsd error: %v", strings.TrimPrefix(line, "ERR "))
+	}
+	return line, nil
+}
+
+func nutQuote(s string) string {
+	return strconv.Quote(s)
+}
+
+// nutUnquote value in double quotes, only backslash and double quote are escaped by upsd
+func nutUnquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("value is not quoted")
+	}
+	var b strings.Builder
+	escaped := false
+	for _, c := range s[1 : len(s)-1] {
+		if c == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(c)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,116 @@
+package shutd
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeUPSD stands in for upsd serving ups.status of single UPS
+type fakeUPSD struct {
+	mu       sync.Mutex
+	ups      string
+	status   string
+	commands []string
+	ln       net.Listener
+}
+
+func startFakeUPSD(t *testing.T, ups, status string) *fakeUPSD {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &fakeUPSD{ups: ups, status: status, ln: ln}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go d.serve(conn)
+		}
+	}()
+	return d
+}
+
+func (d *fakeUPSD) serve(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		command := scanner.Text()
+		d.mu.Lock()
+		d.commands = append(d.commands, command)
+		status := d.status
+		d.mu.Unlock()
+		switch {
+		case strings.HasPrefix(command, "USERNAME "), strings.HasPrefix(command, "PASSWORD "):
+			fmt.Fprint(conn, "OK\n")
+		case command == fmt.Sprintf("GET VAR %v ups.status", d.ups):
+			fmt.Fprintf(conn, "VAR %v ups.status %q\n", d.ups, status)
+		case strings.HasPrefix(command, "GET VAR "):
+			fmt.Fprint(conn, "ERR UNKNOWN-UPS\n")
+		case command == "LOGOUT":
+			fmt.Fprint(conn, "OK Goodbye\n")
+			return
+		default:
+			fmt.Fprint(conn, "ERR UNKNOWN-COMMAND\n")
+		}
+	}
+}
+
+func (d *fakeUPSD) setStatus(status string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.status = status
+}
+
+func (d *fakeUPSD) port() int {
+	return d.ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestNUTClientStatus(t *testing.T) {
+	d := startFakeUPSD(t, "ups", "OB LB")
+	c := NUTClient{Addr: d.ln.Addr().String(), Username: "monuser", Password: "secret"}
+
+	status, err := c.Status("ups")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"OB", "LB"}, status)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	assert.Equal(t, []string{`USERNAME "monuser"`, `PASSWORD "secret"`, "GET VAR ups ups.status", "LOGOUT"}, d.commands)
+}
+
+func TestNUTClientUnknownUPS(t *testing.T) {
+	d := startFakeUPSD(t, "ups", "OL")
+	c := NUTClient{Addr: d.ln.Addr().String()}
+
+	_, err := c.Status("other")
+	assert.EqualError(t, err, "upsd error: UNKNOWN-UPS")
+}
+
+func TestNUTClientConnectionRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	_, err = NUTClient{Addr: addr}.Status("ups")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect upsd")
+}
+
+func TestNUTUnquote(t *testing.T) {
+	v, err := nutUnquote(`"Back-UPS \"ES\" 700 \\ G2"`)
+	assert.NoError(t, err)
+	assert.Equal(t, `Back-UPS "ES" 700 \ G2`, v)
+
+	_, err = nutUnquote("OL")
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package shutd
+
+import (
+	"fmt"
+	"time"
+)
+
+// ShutdownAt shuts down once at t instead of the next daily shutdown, the daily schedule is restored afterwards.
+// The snooze notification is shown before t, and t has to be within 24 hours. It could not be later than
+// the daily shutdown when Config.Policy restricts delay, and ErrPINRequired is returned for later t when protected
+func (s *Scheduler) ShutdownAt(t time.Time) error {
+	return s.ShutdownAtWithPIN(t, "")
+}
+
+// ShutdownAtWithPIN shuts down once at t, pin is verified when t is later than the daily shutdown and protected by Config.Protect
+func (s *Scheduler) ShutdownAtWithPIN(t time.Time, pin string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	replaces, err := s.oneOffReplacing(t)
+	if err != nil {
+		return err
+	}
+	if t.After(replaces) {
+		err = s.checkPIN(pin)
+		if err != nil {
+			return err
+		}
+		// shutdown could be rescheduled or requested by others while PIN is compared
+		replaces, err = s.oneOffReplacing(t)
+		if err != nil {
+			return err
+		}
+	}
+	t = laterTime(t, s.now().Add(minTriggerDelay))
+	return s.shutdownOnce(ShutdownRequest{
+		Reason:    fmt.Sprintf("one-off at %v", t.Format("15:04")),
+		Source:    SourceOneOff,
+		Snoozable: true,
+		Deadline:  t,
+	}, replaces)
+}
+
+// oneOffReplacing returns the daily shutdown time replaced by one-off shutdown at t, error if it is not allowed
+func (s *Scheduler) oneOffReplacing(t time.Time) (time.Time, error) {
+	if s.closed {
+		return time.Time{}, ErrClosed
+	}
+	if s.shutdownJob == nil {
+		return time.Time{}, ErrNotScheduled
+	}
+	now := s.now()
+	if t.Before(now) {
+		return time.Time{}, fmt.Errorf("one-off shutdown time %v is in the past", t.Format(time.RFC3339))
+	}
+	if t.After(now.Add(24 * time.Hour)) {
+		return time.Time{}, fmt.Errorf("one-off shutdown time %v is not within 24 hours", t.Format(time.RFC3339))
+	}
+	if s.request.Source != SourceScheduled && !s.request.Source.oneOff() {
+		return time.Time{}, fmt.Errorf("shutdown is already requested by %v", s.request.Source)
+	}
+	replaces := s.oneOffReplaces
+	if s.request.Source == SourceScheduled {
+		replaces = s.scheduledTime()
+	}
+	if s.config.Policy.restrictsDelay() && t.After(replaces) {
+		return time.Time{}, fmt.Errorf("one-off shutdown later than %v: %w", replaces.Format("15:04"), ErrNotAllowed)
+	}
+	return replaces, nil
+}
+
+// shutdownOnce at r.Deadline instead of the daily shutdown at replaces
+func (s *Scheduler) shutdownOnce(r ShutdownRequest, replaces time.Time) error {
+	s.oneOffReplaces = replaces
+	s.snoozeCount = 0
+	s.request = r
+	err := s.scheduleShutdownJob(r.Deadline)
+	if err != nil {
+		return err
+	}
+	err = s.scheduleSnoozeNotificationJob()
+	if err != nil {
+		return err
+	}
+	s.logEntry(shutdownTag).Info("one-off shutdown scheduled")
+	s.printJobs()
+	return nil
+}
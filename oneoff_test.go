@@ -0,0 +1,121 @@
+package shutd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownAt(t *testing.T) {
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+
+	at := time.Now().Add(time.Hour).Truncate(time.Second)
+	err = s.ShutdownAt(at)
+	assert.NoError(t, err)
+
+	shutdownTime, err := s.ShutdownTime()
+	assert.NoError(t, err)
+	assert.True(t, at.Equal(shutdownTime), "shutdown should be at %v, got %v", at, shutdownTime)
+	assert.True(t, at.Add(-10*time.Minute).Equal(s.snoozeNotificationJob.ScheduledTime()))
+
+	r := s.ShutdownRequest()
+	assert.Equal(t, SourceOneOff, r.Source)
+	assert.True(t, r.Snoozable)
+	assert.Equal(t, "one-off at "+at.Format("15:04"), r.Reason)
+
+	err = s.Snooze()
+	assert.NoError(t, err)
+	shutdownTime, _ = s.ShutdownTime()
+	assert.True(t, at.Add(15*time.Minute).Equal(shutdownTime))
+	assert.Equal(t, SourceOneOff, s.ShutdownRequest().Source)
+}
+
+func TestShutdownAtWithInvalidTime(t *testing.T) {
+	s := getScheduler(t)
+
+	err := s.ShutdownAt(time.Now().Add(-time.Minute))
+	assert.Contains(t, err.Error(), "is in the past")
+
+	err = s.ShutdownAt(time.Now().Add(25 * time.Hour))
+	assert.Contains(t, err.Error(), "is not within 24 hours")
+	assert.Equal(t, SourceScheduled, s.ShutdownRequest().Source)
+}
+
+func TestShutdownAtKeepsEmergencyShutdown(t *testing.T) {
+	s := getScheduler(t)
+	s.mu.Lock()
+	err := s.trigger(ShutdownRequest{Reason: "overheated", Source: SourceThermal, Deadline: time.Now().Add(time.Minute)})
+	s.mu.Unlock()
+	assert.NoError(t, err)
+
+	err = s.ShutdownAt(time.Now().Add(time.Hour))
+	assert.EqualError(t, err, "shutdown is already requested by thermal")
+	assert.Equal(t, SourceThermal, s.ShutdownRequest().Source)
+}
+
+func TestShutdownAtRestoresScheduleAfterwards(t *testing.T) {
+	called := make(chan ShutdownRequest, 1)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		called <- r
+		return nil
+	}
+	dailyTime := time.Now().Add(12 * time.Hour).Format("15:04")
+	s, err := getSchedulerWithConfig(t, getConfigWithShutdownTime(dailyTime), WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+
+	replaced, _ := s.ShutdownTime()
+
+	// deferred to minTriggerDelay
+	err = s.ShutdownAt(time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+
+	select {
+	case r := <-called:
+		assert.Equal(t, SourceOneOff, r.Source)
+	case <-time.After(minTriggerDelay + 2*time.Second):
+		t.Fatal("shutdownTask should be called")
+	}
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Format("15:04") == dailyTime
+	})
+	assert.Equal(t, SourceScheduled, s.ShutdownRequest().Source)
+	// the daily shutdown replaced by the one-off does not happen
+	shutdownTime, _ := s.ShutdownTime()
+	assert.True(t, s.Skipped() || shutdownTime.After(replaced))
+}
+
+func TestRestoreAfterRunSkipsReplacedShutdown(t *testing.T) {
+	s := getScheduler(t)
+	shutdownTime, _ := s.ShutdownTime()
+	err := s.ShutdownAt(shutdownTime.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, s.Skipped())
+
+	s.restoreAfterRun(s.logEntry(shutdownTag), s.request)
+	assert.Equal(t, SourceScheduled, s.ShutdownRequest().Source)
+	assert.True(t, s.Skipped())
+}
+
+func TestConfigureKeepsOneOffShutdown(t *testing.T) {
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+	at := time.Now().Add(time.Hour).Truncate(time.Second)
+	assert.NoError(t, s.ShutdownAt(at))
+	assert.NoError(t, s.Snooze())
+
+	config.StartTime = time.Now().Add(11 * time.Hour).Format("15:04")
+	assert.NoError(t, s.Configure(config))
+	shutdownTime, _ := s.ShutdownTime()
+	assert.True(t, at.Add(15*time.Minute).Equal(shutdownTime), "one-off shutdown should be kept, got %v", shutdownTime)
+	assert.Equal(t, SourceOneOff, s.ShutdownRequest().Source)
+	assert.Equal(t, 1, s.SnoozeCount())
+
+	// the daily shutdown at the new start time is replaced
+	s.restoreAfterRun(s.logEntry(shutdownTag), s.request)
+	assert.True(t, s.Skipped())
+}
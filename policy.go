@@ -0,0 +1,106 @@
+package shutd
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// ActionShutdown forces powering off, even if dry run is set by user
+	ActionShutdown = "shutdown"
+	// ActionDryRun forces dry run, so shutdowns only notify
+	ActionDryRun = "dryRun"
+)
+
+// Policy of administrator that clamps the config of user, e.g. on shared or family computers
+type Policy struct {
+	// LatestStartTime of shutdown, later start time of user is clamped to it and snoozes could not delay past it, e.g. "22:30"
+	LatestStartTime string
+	// MaxSnoozes of each shutdown, user could only set fewer snoozes, 0 for unlimited
+	MaxSnoozes int
+	// DisableSkip disallows skip and pause, skip dates, calendar and skip while charging of user are ignored
+	DisableSkip bool
+	// Action forced regardless of user config, ActionShutdown or ActionDryRun, empty to keep user config
+	Action string
+	// Protect with PIN that user could not remove, it replaces Config.Protect when set
+	Protect ProtectConfig
+}
+
+// Apply policy on top of user config, the policy is kept in Config.Policy to be enforced by scheduler
+func (p Policy) Apply(c Config) Config {
+	if p.LatestStartTime != "" {
+		latest, err := parseClockTime(p.LatestStartTime)
+		start, startErr := parseClockTime(c.StartTime)
+		// invalid times are reported by validation of config
+		if err == nil && startErr == nil && nightClock(start) > nightClock(latest) {
+			c.StartTime = p.LatestStartTime
+		}
+	}
+	if p.MaxSnoozes > 0 && (c.MaxSnoozes <= 0 || c.MaxSnoozes > p.MaxSnoozes) {
+		c.MaxSnoozes = p.MaxSnoozes
+	}
+	if p.DisableSkip {
+		c.SkipDates = nil
+		c.Calendar = CalendarConfig{}
+		c.Battery.SkipWhileCharging = false
+	}
+	if p.Protect.enabled() {
+		c.Protect = p.Protect
+	}
+	switch p.Action {
+	case ActionShutdown:
+		c.DryRun = false
+	case ActionDryRun:
+		c.DryRun = true
+	}
+	c.Policy = p
+	return c
+}
+
+// active returns whether any setting of the policy is set
+func (p Policy) active() bool {
+	return p != Policy{}
+}
+
+// latestShutdown returns the latest shutdown time on the night of t, snoozes could not delay shutdown past it
+func (p Policy) latestShutdown(t time.Time) (time.Time, bool) {
+	if p.LatestStartTime == "" {
+		return time.Time{}, false
+	}
+	latest, err := parseClockTime(p.LatestStartTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return nightOf(t).Add(nightClock(latest)), true
+}
+
+// restrictsDelay returns whether shutdowns may not be delayed by one-off shutdowns later than the daily one
+func (p Policy) restrictsDelay() bool {
+	return p.DisableSkip || p.LatestStartTime != ""
+}
+
+func (p Policy) validate() error {
+	if p.LatestStartTime != "" {
+		_, err := parseClockTime(p.LatestStartTime)
+		if err != nil {
+			return &ConfigError{Field: "policy.latestStartTime", Value: p.LatestStartTime, Err: err}
+		}
+	}
+	if p.MaxSnoozes < 0 {
+		return &ConfigError{Field: "policy.maxSnoozes", Value: p.MaxSnoozes, Err: fmt.Errorf("must not be negative")}
+	}
+	switch p.Action {
+	case "", ActionShutdown, ActionDryRun:
+	default:
+		return &ConfigError{Field: "policy.action", Value: p.Action, Err: fmt.Errorf("must be %v or %v", ActionShutdown, ActionDryRun)}
+	}
+	return nil
+}
+
+// nightClock orders clock times by night, times before noon are after times since noon, e.g. 01:00 is later than 23:00
+func nightClock(clock time.Duration) time.Duration {
+	if clock < 12*time.Hour {
+		return clock + 24*time.Hour
+	}
+	return clock
+}
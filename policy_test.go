@@ -0,0 +1,169 @@
+package shutd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyClampsStartTime(t *testing.T) {
+	policy := Policy{LatestStartTime: "23:00"}
+	tests := []struct {
+		startTime string
+		expected  string
+	}{
+		{"22:00", "22:00"},
+		{"23:00", "23:00"},
+		{"23:30", "23:00"},
+		// after midnight is later in the night
+		{"01:00", "23:00"},
+		{"11:59", "23:00"},
+		{"12:00", "12:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.startTime, func(t *testing.T) {
+			c := policy.Apply(Config{StartTime: tt.startTime})
+			assert.Equal(t, tt.expected, c.StartTime)
+		})
+	}
+}
+
+func TestPolicyClampsMaxSnoozes(t *testing.T) {
+	policy := Policy{MaxSnoozes: 2}
+	assert.Equal(t, 2, policy.Apply(Config{MaxSnoozes: 0}).MaxSnoozes)
+	assert.Equal(t, 2, policy.Apply(Config{MaxSnoozes: 5}).MaxSnoozes)
+	assert.Equal(t, 1, policy.Apply(Config{MaxSnoozes: 1}).MaxSnoozes)
+	assert.Equal(t, 5, Policy{}.Apply(Config{MaxSnoozes: 5}).MaxSnoozes)
+}
+
+func TestPolicyDisablesSkip(t *testing.T) {
+	c := Config{
+		SkipDates: []string{"2026-12-24"},
+		Calendar:  CalendarConfig{ICS: "calendar.ics"},
+		Battery:   BatteryConfig{ShutdownBelow: 10, SkipWhileCharging: true},
+	}
+	c = Policy{DisableSkip: true}.Apply(c)
+	assert.Empty(t, c.SkipDates)
+	assert.Empty(t, c.Calendar.ICS)
+	assert.False(t, c.Battery.SkipWhileCharging)
+	assert.Equal(t, 10, c.Battery.ShutdownBelow)
+	assert.True(t, c.Policy.DisableSkip)
+}
+
+func TestPolicyForcesAction(t *testing.T) {
+	assert.False(t, Policy{Action: ActionShutdown}.Apply(Config{DryRun: true}).DryRun)
+	assert.True(t, Policy{Action: ActionDryRun}.Apply(Config{DryRun: false}).DryRun)
+	assert.True(t, Policy{}.Apply(Config{DryRun: true}).DryRun)
+}
+
+func TestPolicyValidation(t *testing.T) {
+	tests := []struct {
+		policy   Policy
+		expected string
+	}{
+		{Policy{LatestStartTime: "25:00"}, `invalid policy.latestStartTime "25:00": the given time format is not supported`},
+		{Policy{MaxSnoozes: -1}, `invalid policy.maxSnoozes "-1": must not be negative`},
+		{Policy{Action: "reboot"}, `invalid policy.action "reboot": must be shutdown or dryRun`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			c := tt.policy.Apply(getDefaultConfig())
+			_, err := getSchedulerWithConfig(t, c)
+			assert.EqualError(t, err, tt.expected)
+			var configErr *ConfigError
+			assert.True(t, errors.As(err, &configErr))
+		})
+	}
+}
+
+func TestPolicyDisallowsSkipAndPause(t *testing.T) {
+	c := Policy{DisableSkip: true}.Apply(getDefaultConfig())
+	s, err := getSchedulerWithConfig(t, c)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, s.Skip(), ErrNotAllowed)
+	assert.False(t, s.Skipped())
+	assert.ErrorIs(t, s.Pause(), ErrNotAllowed)
+	assert.False(t, s.Paused())
+	assert.NoError(t, s.Snooze())
+}
+
+func TestPolicyDisallowsLaterOneOffShutdown(t *testing.T) {
+	c := getConfigWithShutdownTime(time.Now().Add(2 * time.Hour).Format("15:04"))
+	s, err := getSchedulerWithConfig(t, Policy{DisableSkip: true}.Apply(c))
+	assert.NoError(t, err)
+	shutdownTime, err := s.ShutdownTime()
+	assert.NoError(t, err)
+
+	err = s.ShutdownAt(shutdownTime.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrNotAllowed)
+	assert.Equal(t, SourceScheduled, s.ShutdownRequest().Source)
+
+	err = s.ShutdownAt(time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, SourceOneOff, s.ShutdownRequest().Source)
+}
+
+func TestPolicyKeepsSnoozedShutdownOnConfigure(t *testing.T) {
+	c := getConfigWithShutdownTime(time.Now().Add(2 * time.Hour).Format("15:04"))
+	s, err := getSchedulerWithConfig(t, Policy{MaxSnoozes: 3}.Apply(c))
+	assert.NoError(t, err)
+	assert.NoError(t, s.Snooze())
+	snoozed, _ := s.ShutdownTime()
+
+	c.StartTime = time.Now().Add(3 * time.Hour).Format("15:04")
+	assert.NoError(t, s.Configure(Policy{MaxSnoozes: 3}.Apply(c)))
+	shutdownTime, _ := s.ShutdownTime()
+	assert.Equal(t, snoozed, shutdownTime)
+	assert.Equal(t, 1, s.SnoozeCount())
+
+	// earlier start time still applies
+	c.StartTime = time.Now().Add(time.Hour).Format("15:04")
+	assert.NoError(t, s.Configure(Policy{MaxSnoozes: 3}.Apply(c)))
+	shutdownTime, _ = s.ShutdownTime()
+	assert.Equal(t, c.StartTime, shutdownTime.Format("15:04"))
+}
+
+func TestPolicyResetsUnnotifiedShutdownOnConfigure(t *testing.T) {
+	c := getConfigWithShutdownTime(time.Now().Add(2 * time.Hour).Format("15:04"))
+	s, err := getSchedulerWithConfig(t, Policy{MaxSnoozes: 3}.Apply(c))
+	assert.NoError(t, err)
+
+	c.StartTime = time.Now().Add(3 * time.Hour).Format("15:04")
+	assert.NoError(t, s.Configure(Policy{MaxSnoozes: 3}.Apply(c)))
+	shutdownTime, _ := s.ShutdownTime()
+	assert.Equal(t, c.StartTime, shutdownTime.Format("15:04"))
+}
+
+func TestPolicyLatestShutdown(t *testing.T) {
+	policy := Policy{LatestStartTime: "22:30"}
+	evening := time.Date(2026, 10, 19, 22, 0, 0, 0, time.Local)
+	latest, ok := policy.latestShutdown(evening)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2026, 10, 19, 22, 30, 0, 0, time.Local), latest)
+
+	// after midnight belongs to the previous night
+	latest, _ = policy.latestShutdown(evening.Add(3 * time.Hour))
+	assert.Equal(t, time.Date(2026, 10, 19, 22, 30, 0, 0, time.Local), latest)
+
+	latest, _ = Policy{LatestStartTime: "01:00"}.latestShutdown(evening)
+	assert.Equal(t, time.Date(2026, 10, 20, 1, 0, 0, 0, time.Local), latest)
+
+	_, ok = Policy{}.latestShutdown(evening)
+	assert.False(t, ok)
+}
+
+func TestPolicyDisallowsSnoozePastLatestStartTime(t *testing.T) {
+	c := getConfigWithShutdownTime(time.Now().Add(2 * time.Hour).Format("15:04"))
+	s, err := getSchedulerWithConfig(t, Policy{LatestStartTime: c.StartTime}.Apply(c))
+	assert.NoError(t, err)
+	shutdownTime, _ := s.ShutdownTime()
+
+	assert.False(t, s.ShutdownRequest().Snoozable)
+	assert.ErrorIs(t, s.Snooze(), ErrNotAllowed)
+	snoozed, _ := s.ShutdownTime()
+	assert.Equal(t, shutdownTime, snoozed)
+	assert.Equal(t, 0, s.SnoozeCount())
+}
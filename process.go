@@ -0,0 +1,157 @@
+package shutd
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	processTag                 = "process"
+	defaultProcessPollInterval = 5 * time.Second
+	defaultProcessNotice       = time.Minute
+)
+
+// ProcessConfig for shutdown after watched process exits
+type ProcessConfig struct {
+	// Notice before shutdown after the process exits, the notification could be snoozed, defaults to 1m
+	Notice time.Duration
+	// PollInterval of checking the process, defaults to 5s
+	PollInterval time.Duration
+}
+
+// ProcessWatch to shut down after the process exits, either by PID or by name
+type ProcessWatch struct {
+	PID int
+	// Name of executable, all processes of the name have to exit
+	Name string
+}
+
+// String of the watched process for logs and reason of the shutdown
+func (w ProcessWatch) String() string {
+	if w.Name != "" {
+		return w.Name
+	}
+	return fmt.Sprintf("pid %v", w.PID)
+}
+
+// Processes looks up running processes
+type Processes interface {
+	Running(pid int) (bool, error)
+	// StartTime of running process in units of the platform, it tells apart the process from a later one reusing its PID
+	StartTime(pid int) (uint64, error)
+	// FindByName returns PIDs of running processes with the executable name
+	FindByName(name string) ([]int, error)
+}
+
+// WithProcesses option to allow passing of custom process lookup, used by Scheduler.ShutdownAfter
+func WithProcesses(p Processes) option {
+	return func(s *Scheduler) {
+		s.processes = p
+	}
+}
+
+// ShutdownAfter watches the process and shuts down after it exits, with snooze notification Config.Process.Notice before.
+// It replaces the previous watch, and fails if the process is not running
+func (s *Scheduler) ShutdownAfter(w ProcessWatch) error {
+	if (w.PID > 0) == (w.Name != "") {
+		return fmt.Errorf("either pid or process name has to be given")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	running, err := s.processRunning(w, 0)
+	if err != nil {
+		return err
+	}
+	if !running {
+		return fmt.Errorf("process %v is not running", w)
+	}
+	var started uint64
+	if w.PID > 0 {
+		started, err = s.processes.StartTime(w.PID)
+		if err != nil {
+			return err
+		}
+	}
+	interval := s.config.Process.PollInterval
+	if interval <= 0 {
+		interval = defaultProcessPollInterval
+	}
+	s.watch = &w
+	s.watchStarted = started
+	err = s.scheduleMonitorJob(processTag, true, interval, s.checkProcess)
+	if err != nil {
+		s.watch = nil
+		return err
+	}
+	s.logEntry(processTag).WithField("process", w.String()).Info("watching process")
+	return nil
+}
+
+// WatchedProcess get the process watched by ShutdownAfter, until it exits
+func (s *Scheduler) WatchedProcess() (ProcessWatch, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.watch == nil {
+		return ProcessWatch{}, false
+	}
+	return *s.watch, true
+}
+
+// processRunning returns whether the process of w is running, the process of PID has to be started at started unless it is 0
+func (s *Scheduler) processRunning(w ProcessWatch, started uint64) (bool, error) {
+	if w.Name != "" {
+		pids, err := s.processes.FindByName(w.Name)
+		return len(pids) > 0, err
+	}
+	running, err := s.processes.Running(w.PID)
+	if err != nil || !running || started == 0 {
+		return running, err
+	}
+	// the process has exited and its PID is reused by another process
+	t, err := s.processes.StartTime(w.PID)
+	if err != nil {
+		return false, err
+	}
+	return t == started, nil
+}
+
+// checkProcess begins shutdown once the watched process exits, the watch is removed afterwards
+func (s *Scheduler) checkProcess() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || s.watch == nil {
+		return
+	}
+	w := *s.watch
+	log := s.logEntry(processTag).WithField("process", w.String())
+	running, err := s.processRunning(w, s.watchStarted)
+	if err != nil {
+		log.WithError(err).Error("failed to check process")
+		return
+	}
+	if running {
+		return
+	}
+	s.watch = nil
+	s.scheduler.RemoveByTag(processTag)
+	notice := s.config.Process.Notice
+	if notice <= 0 {
+		notice = defaultProcessNotice
+	}
+	log.Info("process exited: shutdown triggered")
+	if s.request.Source == SourceScheduled && s.shutdownJob != nil {
+		s.oneOffReplaces = s.scheduledTime()
+	}
+	err = s.trigger(ShutdownRequest{
+		Reason:    fmt.Sprintf("%v exited", w),
+		Source:    SourceProcess,
+		Snoozable: true,
+		Deadline:  s.now().Add(notice),
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to reschedule shutdown")
+	}
+}
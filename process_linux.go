@@ -0,0 +1,107 @@
+//go:build linux
+
+package shutd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// statStartTimeField is the start time in fields of stat after command name, field 22 counting from pid
+const statStartTimeField = 22 - 2
+
+// procfsProcesses looks up processes by polling procfs
+type procfsProcesses struct {
+	root string
+}
+
+// NewProcfsProcesses creates Processes reading <root>/proc, root is / except for testing
+func NewProcfsProcesses(root string) Processes {
+	return procfsProcesses{root: root}
+}
+
+func defaultProcesses() Processes {
+	return NewProcfsProcesses("/")
+}
+
+func (p procfsProcesses) Running(pid int) (bool, error) {
+	fields, err := p.stat(pid)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read process %v: %w", pid, err)
+	}
+	// zombie has exited, while waiting for its parent to reap it
+	state := fields[0]
+	return state != "Z" && state != "X", nil
+}
+
+// StartTime of the process in clock ticks since boot, field 22 of /proc/<pid>/stat
+func (p procfsProcesses) StartTime(pid int) (uint64, error) {
+	fields, err := p.stat(pid)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read process %v: %w", pid, err)
+	}
+	if len(fields) < statStartTimeField {
+		return 0, fmt.Errorf("failed to read process %v: unexpected stat format", pid)
+	}
+	started, err := strconv.ParseUint(fields[statStartTimeField-1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read start time of process %v: %w", pid, err)
+	}
+	return started, nil
+}
+
+func (p procfsProcesses) FindByName(name string) ([]int, error) {
+	dirs, err := os.ReadDir(filepath.Join(p.root, "proc"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read processes: %w", err)
+	}
+	var pids []int
+	for _, dir := range dirs {
+		pid, err := strconv.Atoi(dir.Name())
+		if err != nil || !p.hasName(pid, name) {
+			continue
+		}
+		if running, _ := p.Running(pid); running {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+// stat fields of the process from /proc/<pid>/stat after command name in parentheses, starting from state as field 3
+func (p procfsProcesses) stat(pid int) ([]string, error) {
+	stat, err := os.ReadFile(filepath.Join(p.root, "proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return nil, err
+	}
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 {
+		return nil, fmt.Errorf("unexpected stat format")
+	}
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("unexpected stat format")
+	}
+	return fields, nil
+}
+
+// hasName matches comm, which is truncated to 15 characters, or base name of the executable in cmdline
+func (p procfsProcesses) hasName(pid int, name string) bool {
+	dir := filepath.Join(p.root, "proc", strconv.Itoa(pid))
+	if readSysfs(dir, "comm") == name {
+		return true
+	}
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return false
+	}
+	exe := strings.SplitN(string(cmdline), "\x00", 2)[0]
+	return exe != "" && filepath.Base(exe) == name
+}
@@ -0,0 +1,106 @@
+package shutd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func startChildProcess(t *testing.T) *exec.Cmd {
+	cmd := exec.Command("sleep", "30")
+	err := cmd.Start()
+	if err != nil {
+		t.Skipf("failed to start child process: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	return cmd
+}
+
+func TestProcfsProcesses(t *testing.T) {
+	cmd := startChildProcess(t)
+	pid := cmd.Process.Pid
+	processes := NewProcfsProcesses("/")
+
+	running, err := processes.Running(pid)
+	assert.NoError(t, err)
+	assert.True(t, running)
+	started, err := processes.StartTime(pid)
+	assert.NoError(t, err)
+	assert.NotZero(t, started)
+	pids, err := processes.FindByName("sleep")
+	assert.NoError(t, err)
+	assert.Contains(t, pids, pid)
+
+	// not reaped yet, so it is a zombie
+	err = cmd.Process.Kill()
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		running, err := processes.Running(pid)
+		return err == nil && !running
+	}, time.Second, 10*time.Millisecond)
+	pids, err = processes.FindByName("sleep")
+	assert.NoError(t, err)
+	assert.NotContains(t, pids, pid)
+
+	cmd.Wait()
+	running, err = processes.Running(pid)
+	assert.NoError(t, err)
+	assert.False(t, running)
+}
+
+func TestProcfsProcessesMatchesCmdline(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "proc", "42")
+	err := os.MkdirAll(dir, 0755)
+	assert.NoError(t, err)
+	os.WriteFile(filepath.Join(dir, "stat"), []byte("42 (very-long-proc) S 1 42 42 0 -1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "comm"), []byte("very-long-proce\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "cmdline"), []byte("/usr/bin/very-long-process-name\x00--flag\x00"), 0644)
+	os.MkdirAll(filepath.Join(root, "proc", "self"), 0755)
+
+	pids, err := NewProcfsProcesses(root).FindByName("very-long-process-name")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{42}, pids)
+	pids, err = NewProcfsProcesses(root).FindByName("very-long-proce")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{42}, pids)
+}
+
+func TestProcfsProcessesStartTime(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "proc", "42")
+	err := os.MkdirAll(dir, 0755)
+	assert.NoError(t, err)
+	// command name could contain spaces and parentheses
+	stat := "42 (a) b (c) S 1 42 42 0 -1 4194560 100 0 0 0 1 2 0 0 20 0 1 0 123456 1000 10\n"
+	os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0644)
+
+	started, err := NewProcfsProcesses(root).StartTime(42)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(123456), started)
+	_, err = NewProcfsProcesses(root).StartTime(43)
+	assert.Error(t, err)
+}
+
+func TestShutdownAfterChildProcessExits(t *testing.T) {
+	cmd := startChildProcess(t)
+	s, config := getProcessScheduler(t, defaultProcesses())
+	events := s.Subscribe()
+
+	err := s.ShutdownAfter(ProcessWatch{PID: cmd.Process.Pid})
+	assert.NoError(t, err)
+
+	cmd.Process.Kill()
+	cmd.Wait()
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Before(time.Now().Add(config.Process.Notice + time.Second))
+	})
+	assert.Equal(t, SourceProcess, s.ShutdownRequest().Source)
+}
@@ -0,0 +1,23 @@
+//go:build !linux && !windows
+
+package shutd
+
+import "fmt"
+
+type unsupportedProcesses struct{}
+
+func defaultProcesses() Processes {
+	return unsupportedProcesses{}
+}
+
+func (unsupportedProcesses) Running(pid int) (bool, error) {
+	return false, fmt.Errorf("watching processes is not supported on this platform")
+}
+
+func (unsupportedProcesses) StartTime(pid int) (uint64, error) {
+	return 0, fmt.Errorf("watching processes is not supported on this platform")
+}
+
+func (unsupportedProcesses) FindByName(name string) ([]int, error) {
+	return nil, fmt.Errorf("watching processes is not supported on this platform")
+}
@@ -0,0 +1,141 @@
+package shutd
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeProcesses struct {
+	mu      sync.Mutex
+	running map[int]string
+	// reused counts the processes started later with the same PID
+	reused map[int]uint64
+}
+
+func (p *fakeProcesses) Running(pid int) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.running[pid]
+	return ok, nil
+}
+
+func (p *fakeProcesses) StartTime(pid int) (uint64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return 1 + p.reused[pid], nil
+}
+
+func (p *fakeProcesses) FindByName(name string) ([]int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var pids []int
+	for pid, n := range p.running {
+		if n == name {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+func (p *fakeProcesses) exit(pid int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.running, pid)
+}
+
+// reuse the PID of exited process by another process of name
+func (p *fakeProcesses) reuse(pid int, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.reused == nil {
+		p.reused = map[int]uint64{}
+	}
+	p.reused[pid]++
+	p.running[pid] = name
+}
+
+func getProcessScheduler(t *testing.T, processes Processes) (*Scheduler, Config) {
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	config.Process = ProcessConfig{Notice: 30 * time.Second, PollInterval: 50 * time.Millisecond}
+	s, err := getSchedulerWithConfig(t, config, WithProcesses(processes))
+	assert.NoError(t, err)
+	return s, config
+}
+
+func TestShutdownAfterProcessExits(t *testing.T) {
+	processes := &fakeProcesses{running: map[int]string{42: "rsync"}}
+	s, config := getProcessScheduler(t, processes)
+	events := s.Subscribe()
+
+	err := s.ShutdownAfter(ProcessWatch{PID: 42})
+	assert.NoError(t, err)
+	w, ok := s.WatchedProcess()
+	assert.True(t, ok)
+	assert.Equal(t, "pid 42", w.String())
+
+	processes.exit(42)
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Before(time.Now().Add(config.Process.Notice + time.Second))
+	})
+	r := s.ShutdownRequest()
+	assert.Equal(t, SourceProcess, r.Source)
+	assert.Equal(t, "pid 42 exited", r.Reason)
+	assert.True(t, r.Snoozable)
+	_, ok = s.WatchedProcess()
+	assert.False(t, ok)
+}
+
+func TestShutdownAfterProcessExitsAndPIDIsReused(t *testing.T) {
+	processes := &fakeProcesses{running: map[int]string{42: "rsync"}}
+	s, config := getProcessScheduler(t, processes)
+	events := s.Subscribe()
+
+	err := s.ShutdownAfter(ProcessWatch{PID: 42})
+	assert.NoError(t, err)
+
+	processes.exit(42)
+	processes.reuse(42, "bash")
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Before(time.Now().Add(config.Process.Notice + time.Second))
+	})
+	assert.Equal(t, "pid 42 exited", s.ShutdownRequest().Reason)
+}
+
+func TestShutdownAfterAllProcessesOfNameExit(t *testing.T) {
+	processes := &fakeProcesses{running: map[int]string{42: "rsync", 43: "rsync", 44: "bash"}}
+	s, config := getProcessScheduler(t, processes)
+	events := s.Subscribe()
+
+	err := s.ShutdownAfter(ProcessWatch{Name: "rsync"})
+	assert.NoError(t, err)
+
+	processes.exit(42)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, SourceScheduled, s.ShutdownRequest().Source)
+
+	processes.exit(43)
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Before(time.Now().Add(config.Process.Notice + time.Second))
+	})
+	assert.Equal(t, "rsync exited", s.ShutdownRequest().Reason)
+}
+
+func TestShutdownAfterProcessNotRunning(t *testing.T) {
+	processes := &fakeProcesses{running: map[int]string{}}
+	s, _ := getProcessScheduler(t, processes)
+
+	err := s.ShutdownAfter(ProcessWatch{PID: 42})
+	assert.EqualError(t, err, "process pid 42 is not running")
+	err = s.ShutdownAfter(ProcessWatch{Name: "rsync"})
+	assert.EqualError(t, err, "process rsync is not running")
+	err = s.ShutdownAfter(ProcessWatch{})
+	assert.EqualError(t, err, "either pid or process name has to be given")
+	err = s.ShutdownAfter(ProcessWatch{PID: 42, Name: "rsync"})
+	assert.EqualError(t, err, "either pid or process name has to be given")
+
+	_, ok := s.WatchedProcess()
+	assert.False(t, ok)
+}
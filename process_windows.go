@@ -0,0 +1,75 @@
+//go:build windows
+
+package shutd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const stillActive = 259
+
+// toolhelpProcesses looks up processes via OpenProcess and process snapshot of Tool Help
+type toolhelpProcesses struct{}
+
+func defaultProcesses() Processes {
+	return toolhelpProcesses{}
+}
+
+func (toolhelpProcesses) Running(pid int) (bool, error) {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if errors.Is(err, windows.ERROR_INVALID_PARAMETER) {
+		// no process with the pid
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to open process %v: %w", pid, err)
+	}
+	defer windows.CloseHandle(h)
+	var code uint32
+	err = windows.GetExitCodeProcess(h, &code)
+	if err != nil {
+		return false, fmt.Errorf("failed to get exit code of process %v: %w", pid, err)
+	}
+	return code == stillActive, nil
+}
+
+// StartTime is the creation time of the process
+func (toolhelpProcesses) StartTime(pid int) (uint64, error) {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open process %v: %w", pid, err)
+	}
+	defer windows.CloseHandle(h)
+	var creation, exit, kernel, user windows.Filetime
+	err = windows.GetProcessTimes(h, &creation, &exit, &kernel, &user)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get times of process %v: %w", pid, err)
+	}
+	return uint64(creation.Nanoseconds()), nil
+}
+
+// FindByName matches executable name case insensitively, with or without .exe extension
+func (toolhelpProcesses) FindByName(name string) ([]int, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot processes: %w", err)
+	}
+	defer windows.CloseHandle(snapshot)
+	var pids []int
+	entry := windows.ProcessEntry32{Size: uint32(unsafe.Sizeof(windows.ProcessEntry32{}))}
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		exe := windows.UTF16ToString(entry.ExeFile[:])
+		if strings.EqualFold(exe, name) || strings.EqualFold(exe, name+".exe") {
+			pids = append(pids, int(entry.ProcessID))
+		}
+	}
+	if !errors.Is(err, windows.ERROR_NO_MORE_FILES) {
+		return nil, fmt.Errorf("failed to read processes: %w", err)
+	}
+	return pids, nil
+}
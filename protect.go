@@ -0,0 +1,132 @@
+package shutd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	defaultMaxPINAttempts = 5
+	defaultPINLockout     = 5 * time.Minute
+)
+
+// ProtectConfig to require PIN for snooze, skip and pause, e.g. for parental control
+type ProtectConfig struct {
+	// PINHash of bcrypt, e.g. generated by "shutd hash-pin", empty to disable
+	PINHash string
+	// MaxAttempts of wrong PIN before locked out, defaults to 5
+	MaxAttempts int
+	// Lockout after too many wrong PINs, defaults to 5m
+	Lockout time.Duration
+}
+
+func (c ProtectConfig) enabled() bool {
+	return c.PINHash != ""
+}
+
+func (c ProtectConfig) validate() error {
+	if !c.enabled() {
+		return nil
+	}
+	_, err := bcrypt.Cost([]byte(c.PINHash))
+	if err != nil {
+		return &ConfigError{Field: "protect.pinHash", Value: "***", Err: err}
+	}
+	return nil
+}
+
+// HashPIN with bcrypt for ProtectConfig.PINHash
+func HashPIN(pin string) (string, error) {
+	if pin == "" {
+		return "", fmt.Errorf("PIN must not be empty")
+	}
+	b, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash PIN: %w", err)
+	}
+	return string(b), nil
+}
+
+// PINAsker is implemented by notifiers which could ask for PIN, so protected shutdown could be snoozed from notification
+type PINAsker interface {
+	AskPIN(ctx context.Context, title, text string) (string, error)
+}
+
+// Protected returns whether snooze, skip and pause require PIN
+func (s *Scheduler) Protected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config.Protect.enabled()
+}
+
+// VerifyPIN when protected by Config.Protect, e.g. before switching profile, wrong PINs are rate limited as for snooze
+func (s *Scheduler) VerifyPIN(pin string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	return s.checkPIN(pin)
+}
+
+// checkPIN when protected, attempts are rate limited by ProtectConfig.MaxAttempts and ProtectConfig.Lockout.
+// It is called with lock held, the lock is released while comparing PIN as bcrypt is slow by design,
+// so callers have to check their state again once it returns. The attempt is counted before comparing,
+// so concurrent wrong PINs could not exceed the attempts, and it is refunded unless the PIN is wrong
+func (s *Scheduler) checkPIN(pin string) error {
+	config := s.config.Protect
+	if !config.enabled() {
+		return nil
+	}
+	if pin == "" {
+		return ErrPINRequired
+	}
+	if s.now().Before(s.pinLockedUntil) {
+		return fmt.Errorf("%w, try again after %v", ErrPINLocked, s.pinLockedUntil.Format("15:04:05"))
+	}
+	maxAttempts := config.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxPINAttempts
+	}
+	if s.pinAttempts >= maxAttempts {
+		// remaining attempts are taken by PINs being compared
+		return fmt.Errorf("%w, try again later", ErrPINLocked)
+	}
+	s.pinAttempts++
+	s.mu.Unlock()
+	err := bcrypt.CompareHashAndPassword([]byte(config.PINHash), []byte(pin))
+	s.mu.Lock()
+	now := s.now()
+	if err == nil {
+		s.pinAttempts = 0
+		return nil
+	}
+	if !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		if s.pinAttempts > 0 {
+			s.pinAttempts--
+		}
+		return fmt.Errorf("failed to verify PIN: %w", err)
+	}
+	log := s.logEntry(shutdownTag).WithField("attempts", s.pinAttempts)
+	if now.Before(s.pinLockedUntil) {
+		// locked out by other wrong PINs meanwhile
+		log.Warn("wrong PIN")
+		return fmt.Errorf("%w, try again after %v", ErrPINLocked, s.pinLockedUntil.Format("15:04:05"))
+	}
+	if s.pinAttempts < maxAttempts {
+		log.Warn("wrong PIN")
+		return ErrWrongPIN
+	}
+	lockout := config.Lockout
+	if lockout <= 0 {
+		lockout = defaultPINLockout
+	}
+	s.pinAttempts = 0
+	s.pinLockedUntil = now.Add(lockout)
+	log.WithField("until", s.pinLockedUntil.Format(time.RFC3339)).Warn("wrong PIN: locked out")
+	return fmt.Errorf("%w, try again after %v", ErrPINLocked, s.pinLockedUntil.Format("15:04:05"))
+}
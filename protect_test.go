@@ -0,0 +1,237 @@
+package shutd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func getProtectedConfig(t *testing.T, pin string) Config {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.MinCost)
+	assert.NoError(t, err)
+	config := getDefaultConfig()
+	config.Protect = ProtectConfig{PINHash: string(hash), MaxAttempts: 3, Lockout: time.Minute}
+	return config
+}
+
+// pinNotifier answers snooze and then the PINs in order
+type pinNotifier struct {
+	pins  []string
+	texts []string
+}
+
+func (n *pinNotifier) Question(ctx context.Context, title, text string) (bool, error) {
+	return true, nil
+}
+
+func (n *pinNotifier) AskPIN(ctx context.Context, title, text string) (string, error) {
+	n.texts = append(n.texts, text)
+	if len(n.pins) == 0 {
+		return "", nil
+	}
+	pin := n.pins[0]
+	n.pins = n.pins[1:]
+	return pin, nil
+}
+
+func TestHashPIN(t *testing.T) {
+	hash, err := HashPIN("1234")
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("1234")))
+
+	_, err = HashPIN("")
+	assert.EqualError(t, err, "PIN must not be empty")
+}
+
+func TestProtectedActionsRequirePIN(t *testing.T) {
+	s, err := getSchedulerWithConfig(t, getProtectedConfig(t, "1234"))
+	assert.NoError(t, err)
+	assert.True(t, s.Protected())
+
+	assert.ErrorIs(t, s.Snooze(), ErrPINRequired)
+	assert.ErrorIs(t, s.Skip(), ErrPINRequired)
+	assert.ErrorIs(t, s.Pause(), ErrPINRequired)
+	assert.ErrorIs(t, s.SnoozeWithPIN("0000"), ErrWrongPIN)
+	assert.Equal(t, 0, s.SnoozeCount())
+
+	assert.NoError(t, s.SnoozeWithPIN("1234"))
+	assert.Equal(t, 1, s.SnoozeCount())
+	assert.NoError(t, s.SkipWithPIN("1234"))
+	assert.True(t, s.Skipped())
+	assert.NoError(t, s.PauseWithPIN("1234"))
+	assert.True(t, s.Paused())
+}
+
+func TestProtectedActionsLockedOutAfterWrongPINs(t *testing.T) {
+	s, err := getSchedulerWithConfig(t, getProtectedConfig(t, "1234"))
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, s.SnoozeWithPIN("0000"), ErrWrongPIN)
+	assert.ErrorIs(t, s.SkipWithPIN("0001"), ErrWrongPIN)
+	err = s.PauseWithPIN("0002")
+	assert.ErrorIs(t, err, ErrPINLocked)
+	assert.True(t, strings.HasPrefix(err.Error(), "too many wrong PINs, try again after "))
+
+	// correct PIN is refused during lockout
+	assert.ErrorIs(t, s.SnoozeWithPIN("1234"), ErrPINLocked)
+	assert.Equal(t, 0, s.SnoozeCount())
+
+	s.mu.Lock()
+	s.pinLockedUntil = time.Now().Add(-time.Second)
+	s.mu.Unlock()
+	assert.NoError(t, s.SnoozeWithPIN("1234"))
+}
+
+func TestCorrectPINResetsAttempts(t *testing.T) {
+	s, err := getSchedulerWithConfig(t, getProtectedConfig(t, "1234"))
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, s.SnoozeWithPIN("0000"), ErrWrongPIN)
+		assert.NoError(t, s.SnoozeWithPIN("1234"))
+	}
+	assert.ErrorIs(t, s.SnoozeWithPIN("0000"), ErrWrongPIN)
+}
+
+func TestProtectWithInvalidPINHash(t *testing.T) {
+	config := getDefaultConfig()
+	config.Protect.PINHash = "1234"
+	_, err := getSchedulerWithConfig(t, config)
+	assert.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), `invalid protect.pinHash "***"`), err.Error())
+}
+
+func TestSnoozeNotificationAsksForPIN(t *testing.T) {
+	notifier := &pinNotifier{pins: []string{"0000", "1234"}}
+	config := getProtectedConfig(t, "1234")
+	config.Notification.Notifier = "test"
+	s, err := getSchedulerWithConfig(t, config, WithNotifier("test", notifier))
+	assert.NoError(t, err)
+
+	err = newNotificationSnoozeTask()(s, s.ShutdownRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Enter PIN to snooze", "Wrong PIN, enter PIN to snooze"}, notifier.texts)
+	assert.Equal(t, 1, s.SnoozeCount())
+}
+
+func TestSnoozeNotificationCancelledPIN(t *testing.T) {
+	notifier := &pinNotifier{}
+	config := getProtectedConfig(t, "1234")
+	config.Notification.Notifier = "test"
+	s, err := getSchedulerWithConfig(t, config, WithNotifier("test", notifier))
+	assert.NoError(t, err)
+
+	err = newNotificationSnoozeTask()(s, s.ShutdownRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, s.SnoozeCount())
+}
+
+func TestSnoozeNotificationWithoutPINAsker(t *testing.T) {
+	notifier := NotifierFunc(func(ctx context.Context, title, text string) (bool, error) {
+		return true, nil
+	})
+	config := getProtectedConfig(t, "1234")
+	config.Notification.Notifier = "test"
+	s, err := getSchedulerWithConfig(t, config, WithNotifier("test", notifier))
+	assert.NoError(t, err)
+
+	err = newNotificationSnoozeTask()(s, s.ShutdownRequest())
+	assert.ErrorIs(t, err, ErrPINRequired)
+	assert.Equal(t, 0, s.SnoozeCount())
+}
+
+func TestPolicyProtectReplacesUserProtect(t *testing.T) {
+	c := Config{Protect: ProtectConfig{PINHash: "user"}}
+	assert.Equal(t, "user", Policy{}.Apply(c).Protect.PINHash)
+	assert.Equal(t, "admin", Policy{Protect: ProtectConfig{PINHash: "admin"}}.Apply(c).Protect.PINHash)
+}
+
+func TestProtectedShutdownAtLaterRequiresPIN(t *testing.T) {
+	config := getProtectedConfig(t, "1234")
+	config.StartTime = time.Now().Add(2 * time.Hour).Format("15:04")
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+	shutdownTime, _ := s.ShutdownTime()
+
+	// earlier one-off shutdown does not need PIN
+	assert.NoError(t, s.ShutdownAt(time.Now().Add(time.Hour)))
+	assert.ErrorIs(t, s.ShutdownAt(shutdownTime.Add(time.Hour)), ErrPINRequired)
+	assert.ErrorIs(t, s.ShutdownAtWithPIN(shutdownTime.Add(time.Hour), "0000"), ErrWrongPIN)
+	assert.NoError(t, s.ShutdownAtWithPIN(shutdownTime.Add(time.Hour), "1234"))
+}
+
+func TestVerifyPIN(t *testing.T) {
+	s, err := getSchedulerWithConfig(t, getProtectedConfig(t, "1234"))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, s.VerifyPIN(""), ErrPINRequired)
+	assert.ErrorIs(t, s.VerifyPIN("0000"), ErrWrongPIN)
+	assert.NoError(t, s.VerifyPIN("1234"))
+
+	s = getScheduler(t)
+	assert.NoError(t, s.VerifyPIN(""))
+}
+
+func TestProtectedSnoozedShutdownIsKeptOnConfigure(t *testing.T) {
+	config := getProtectedConfig(t, "1234")
+	config.StartTime = time.Now().Add(2 * time.Hour).Format("15:04")
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+	assert.NoError(t, s.SnoozeWithPIN("1234"))
+	snoozed, _ := s.ShutdownTime()
+
+	// e.g. by switching profile
+	config.StartTime = time.Now().Add(3 * time.Hour).Format("15:04")
+	assert.NoError(t, s.Configure(config))
+	shutdownTime, _ := s.ShutdownTime()
+	assert.Equal(t, snoozed, shutdownTime)
+	assert.Equal(t, 1, s.SnoozeCount())
+}
+
+func TestWrongPINDoesNotBlockScheduler(t *testing.T) {
+	// slow hash, so the lock would be held noticeably while comparing
+	hash, err := bcrypt.GenerateFromPassword([]byte("1234"), 11)
+	assert.NoError(t, err)
+	config := getDefaultConfig()
+	config.Protect = ProtectConfig{PINHash: string(hash)}
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+
+	compared := make(chan error)
+	go func() {
+		compared <- s.SnoozeWithPIN("0000")
+	}()
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	assert.Equal(t, 0, s.SnoozeCount())
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "getter should not wait for PIN comparison")
+	assert.ErrorIs(t, <-compared, ErrWrongPIN)
+}
+
+func TestConcurrentWrongPINsAreLockedOut(t *testing.T) {
+	s, err := getSchedulerWithConfig(t, getProtectedConfig(t, "1234"))
+	assert.NoError(t, err)
+
+	const attempts = 10
+	errs := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		go func() {
+			errs <- s.VerifyPIN("0000")
+		}()
+	}
+	wrong := 0
+	for i := 0; i < attempts; i++ {
+		err := <-errs
+		if errors.Is(err, ErrWrongPIN) {
+			wrong++
+		} else {
+			assert.ErrorIs(t, err, ErrPINLocked)
+		}
+	}
+	assert.LessOrEqual(t, wrong, 2, "wrong PINs beyond max attempts should not be compared")
+	assert.ErrorIs(t, s.VerifyPIN("1234"), ErrPINLocked)
+}
@@ -0,0 +1,145 @@
+package shutd
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	quietTag                 = "quiet"
+	defaultQuietPollInterval = 10 * time.Second
+	defaultQuietFor          = 10 * time.Minute
+)
+
+// QuietConfig for shutdown when CPU and network are quiet, e.g. after downloads or builds finish
+type QuietConfig struct {
+	// CPUBelow percent of CPU usage, 0 to ignore CPU
+	CPUBelow float64
+	// NetBelowKBps of network throughput received and sent in KB/s, 0 to ignore network
+	NetBelowKBps float64
+	// For how long it stays quiet before shutdown, defaults to 10m
+	For time.Duration
+	// PollInterval of reading CPU and network counters, defaults to 10s
+	PollInterval time.Duration
+}
+
+func (c QuietConfig) enabled() bool {
+	return c.CPUBelow > 0 || c.NetBelowKBps > 0
+}
+
+// LoadSample of cumulative CPU time and network bytes since boot, usage is the difference between samples
+type LoadSample struct {
+	CPUBusy  uint64
+	CPUTotal uint64
+	// NetBytes received and sent by all interfaces except loopback
+	NetBytes uint64
+}
+
+// Load reads CPU and network counters of the computer
+type Load interface {
+	ReadLoad() (LoadSample, error)
+}
+
+// WithLoad option to allow passing of custom CPU and network counters, used when Config.Quiet is set
+func WithLoad(l Load) option {
+	return func(s *Scheduler) {
+		s.load = l
+	}
+}
+
+// scheduleQuietJob to poll CPU and network counters, the job is removed when quiet config is not set.
+// The quiet state is reset only when the config is changed from previous
+func (s *Scheduler) scheduleQuietJob(previous QuietConfig) error {
+	if s.config.Quiet != previous {
+		s.loadSampledAt = time.Time{}
+		s.quietSince = time.Time{}
+		s.quiet = false
+		s.quietArmed = false
+	}
+	interval := s.config.Quiet.PollInterval
+	if interval <= 0 {
+		interval = defaultQuietPollInterval
+	}
+	return s.scheduleMonitorJob(quietTag, s.config.Quiet.enabled(), interval, s.checkQuiet)
+}
+
+// checkQuiet begins shutdown when CPU and network stay below thresholds after being busy, so an idle computer is not
+// shut down just for being idle. The schedule is restored once busy again
+func (s *Scheduler) checkQuiet() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	sample, err := s.load.ReadLoad()
+	if err != nil {
+		s.logEntry(quietTag).WithError(err).Error("failed to read load")
+		return
+	}
+	now := s.now()
+	previous, previousAt := s.loadSample, s.loadSampledAt
+	s.loadSample, s.loadSampledAt = sample, now
+	if previousAt.IsZero() {
+		return
+	}
+	cpu, net := loadUsage(previous, sample, now.Sub(previousAt))
+	config := s.config.Quiet
+	log := s.logEntry(quietTag).WithField("cpu", fmt.Sprintf("%.1f%%", cpu)).WithField("net", fmt.Sprintf("%.1fKB/s", net))
+	quiet := (config.CPUBelow <= 0 || cpu < config.CPUBelow) && (config.NetBelowKBps <= 0 || net < config.NetBelowKBps)
+	if !quiet {
+		s.quietSince = time.Time{}
+		if !s.quietArmed {
+			s.quietArmed = true
+			log.Info("busy: armed shutdown once quiet")
+		}
+		if s.quiet {
+			s.quiet = false
+			if s.request.Source != SourceQuiet {
+				return
+			}
+			log.Info("no longer quiet: shutdown schedule restored")
+			err = s.restoreSchedule()
+			if err != nil {
+				log.WithError(err).Error("failed to reschedule shutdown")
+			}
+		}
+		return
+	}
+	if !s.quietArmed {
+		return
+	}
+	if s.quietSince.IsZero() {
+		s.quietSince = previousAt
+		log.Info("quiet")
+	}
+	duration := config.For
+	if duration <= 0 {
+		duration = defaultQuietFor
+	}
+	if s.quiet || now.Sub(s.quietSince) < duration {
+		return
+	}
+	s.quiet = true
+	log.Info("quiet for a while: shutdown triggered")
+	err = s.trigger(ShutdownRequest{
+		Reason:    fmt.Sprintf("quiet for %v", formatMinutes(duration)),
+		Source:    SourceQuiet,
+		Snoozable: true,
+		Deadline:  now.Add(s.config.Notification.Before),
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to reschedule shutdown")
+	}
+}
+
+// loadUsage between samples as CPU percent and network KB/s
+func loadUsage(previous, current LoadSample, elapsed time.Duration) (cpu float64, net float64) {
+	// counters going backwards, e.g. interface removed, are treated as idle
+	if current.CPUTotal > previous.CPUTotal && current.CPUBusy > previous.CPUBusy {
+		cpu = float64(current.CPUBusy-previous.CPUBusy) / float64(current.CPUTotal-previous.CPUTotal) * 100
+	}
+	if current.NetBytes > previous.NetBytes && elapsed > 0 {
+		net = float64(current.NetBytes-previous.NetBytes) / 1024 / elapsed.Seconds()
+	}
+	return cpu, net
+}
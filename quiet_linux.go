@@ -0,0 +1,93 @@
+//go:build linux
+
+package shutd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procfsLoad reads CPU and network counters from procfs
+type procfsLoad struct {
+	root string
+}
+
+// NewProcfsLoad creates Load reading <root>/proc/stat and <root>/proc/net/dev, root is / except for testing
+func NewProcfsLoad(root string) Load {
+	return procfsLoad{root: root}
+}
+
+func defaultLoad() Load {
+	return NewProcfsLoad("/")
+}
+
+func (l procfsLoad) ReadLoad() (LoadSample, error) {
+	var sample LoadSample
+	stat, err := os.ReadFile(filepath.Join(l.root, "proc", "stat"))
+	if err != nil {
+		return sample, fmt.Errorf("failed to read cpu stat: %w", err)
+	}
+	sample.CPUBusy, sample.CPUTotal, err = parseCPUStat(string(stat))
+	if err != nil {
+		return sample, err
+	}
+	dev, err := os.ReadFile(filepath.Join(l.root, "proc", "net", "dev"))
+	if err != nil {
+		return sample, fmt.Errorf("failed to read network stat: %w", err)
+	}
+	sample.NetBytes, err = parseNetDev(string(dev))
+	return sample, err
+}
+
+// parseCPUStat sums the aggregated cpu line, guest time is already counted in user time
+func parseCPUStat(stat string) (busy uint64, total uint64, err error) {
+	for _, line := range strings.Split(stat, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		// user nice system idle iowait irq softirq steal
+		for i, field := range fields[1:] {
+			if i >= 8 {
+				break
+			}
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, 0, fmt.Errorf("invalid cpu stat %q: %v", line, err)
+			}
+			total += v
+			if i != 3 && i != 4 {
+				busy += v
+			}
+		}
+		return busy, total, nil
+	}
+	return 0, 0, fmt.Errorf("cpu stat is not found")
+}
+
+// parseNetDev sums received and sent bytes of interfaces except loopback
+func parseNetDev(dev string) (uint64, error) {
+	var bytes uint64
+	for _, line := range strings.Split(dev, "\n") {
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		name := strings.TrimSpace(line[:i])
+		fields := strings.Fields(line[i+1:])
+		if name == "lo" || len(fields) < 9 {
+			continue
+		}
+		for _, field := range []string{fields[0], fields[8]} {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid network stat of %v: %v", name, err)
+			}
+			bytes += v
+		}
+	}
+	return bytes, nil
+}
@@ -0,0 +1,54 @@
+package shutd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testProcStat = `cpu  100 10 50 800 20 5 5 10 7 0
+cpu0 50 5 25 400 10 2 3 5 7 0
+intr 12345
+`
+
+const testNetDev = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo: 9999999     100    0    0    0     0          0         0  9999999     100    0    0    0     0       0          0
+  eth0:    1000      10    0    0    0     0          0         0      500       5    0    0    0     0       0          0
+ wlan0:     200       2    0    0    0     0          0         0      100       1    0    0    0     0       0          0
+`
+
+func TestProcfsLoad(t *testing.T) {
+	root := t.TempDir()
+	err := os.MkdirAll(filepath.Join(root, "proc", "net"), 0755)
+	assert.NoError(t, err)
+	os.WriteFile(filepath.Join(root, "proc", "stat"), []byte(testProcStat), 0644)
+	os.WriteFile(filepath.Join(root, "proc", "net", "dev"), []byte(testNetDev), 0644)
+
+	sample, err := NewProcfsLoad(root).ReadLoad()
+	assert.NoError(t, err)
+	assert.Equal(t, LoadSample{CPUBusy: 180, CPUTotal: 1000, NetBytes: 1800}, sample)
+}
+
+func TestProcfsLoadWithInvalidStat(t *testing.T) {
+	root := t.TempDir()
+	err := os.MkdirAll(filepath.Join(root, "proc", "net"), 0755)
+	assert.NoError(t, err)
+	os.WriteFile(filepath.Join(root, "proc", "stat"), []byte("intr 12345\n"), 0644)
+
+	_, err = NewProcfsLoad(root).ReadLoad()
+	assert.EqualError(t, err, "cpu stat is not found")
+
+	_, err = NewProcfsLoad(t.TempDir()).ReadLoad()
+	assert.Contains(t, err.Error(), "failed to read cpu stat")
+}
+
+func TestProcfsLoadOfThisMachine(t *testing.T) {
+	sample, err := defaultLoad().ReadLoad()
+	if err != nil {
+		t.Skipf("procfs is not available: %v", err)
+	}
+	assert.True(t, sample.CPUTotal >= sample.CPUBusy)
+}
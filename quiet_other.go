@@ -0,0 +1,15 @@
+//go:build !linux
+
+package shutd
+
+import "fmt"
+
+type unsupportedLoad struct{}
+
+func defaultLoad() Load {
+	return unsupportedLoad{}
+}
+
+func (unsupportedLoad) ReadLoad() (LoadSample, error) {
+	return LoadSample{}, fmt.Errorf("reading CPU and network load is not supported on this platform")
+}
@@ -0,0 +1,119 @@
+package shutd
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLoad advances counters on every read, by busy CPU percent and network bytes
+type fakeLoad struct {
+	mu       sync.Mutex
+	sample   LoadSample
+	cpu      uint64
+	netBytes uint64
+}
+
+func (l *fakeLoad) ReadLoad() (LoadSample, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sample.CPUBusy += l.cpu
+	l.sample.CPUTotal += 100
+	l.sample.NetBytes += l.netBytes
+	return l.sample, nil
+}
+
+func (l *fakeLoad) set(cpu, netBytes uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cpu = cpu
+	l.netBytes = netBytes
+}
+
+func getQuietConfig() Config {
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	config.Quiet = QuietConfig{CPUBelow: 10, NetBelowKBps: 50, For: 200 * time.Millisecond, PollInterval: 50 * time.Millisecond}
+	return config
+}
+
+func TestQuietTriggersShutdown(t *testing.T) {
+	load := &fakeLoad{cpu: 50}
+	config := getQuietConfig()
+	s, err := getSchedulerWithConfig(t, config, WithLoad(load))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+
+	time.Sleep(150 * time.Millisecond)
+	load.set(5, 0)
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Before(time.Now().Add(config.Notification.Before + time.Second))
+	})
+	r := s.ShutdownRequest()
+	assert.Equal(t, SourceQuiet, r.Source)
+	assert.True(t, r.Snoozable)
+
+	// 1MB for each 50ms poll
+	load.set(5, 1024*1024)
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Format("15:04") == config.StartTime
+	})
+	assert.Equal(t, SourceScheduled, s.ShutdownRequest().Source)
+}
+
+func TestBusyDoesNotTriggerShutdown(t *testing.T) {
+	load := &fakeLoad{cpu: 50}
+	s, err := getSchedulerWithConfig(t, getQuietConfig(), WithLoad(load))
+	assert.NoError(t, err)
+
+	time.Sleep(400 * time.Millisecond)
+	assert.Equal(t, SourceScheduled, s.ShutdownRequest().Source)
+}
+
+func TestIdleWithoutBusyDoesNotTriggerShutdown(t *testing.T) {
+	load := &fakeLoad{cpu: 5}
+	s, err := getSchedulerWithConfig(t, getQuietConfig(), WithLoad(load))
+	assert.NoError(t, err)
+
+	time.Sleep(400 * time.Millisecond)
+	assert.Equal(t, SourceScheduled, s.ShutdownRequest().Source)
+}
+
+func TestQuietShutdownInDryRunRestoresSchedule(t *testing.T) {
+	load := &fakeLoad{cpu: 50}
+	config := getQuietConfig()
+	config.DryRun = true
+	config.Quiet.PollInterval = time.Hour
+	s, err := getSchedulerWithConfig(t, config, WithLoad(load))
+	assert.NoError(t, err)
+
+	s.checkQuiet()
+	s.checkQuiet()
+	load.set(5, 0)
+	s.checkQuiet()
+	time.Sleep(config.Quiet.For)
+	s.checkQuiet()
+	assert.Equal(t, SourceQuiet, s.ShutdownRequest().Source)
+
+	s.runShutdownJob()
+	shutdownTime, _ := s.ShutdownTime()
+	assert.Equal(t, config.StartTime, shutdownTime.Format("15:04"))
+	// it has to be busy again before the next quiet shutdown
+	time.Sleep(config.Quiet.For)
+	s.checkQuiet()
+	s.checkQuiet()
+	assert.Equal(t, SourceScheduled, s.ShutdownRequest().Source)
+}
+
+func TestLoadUsage(t *testing.T) {
+	previous := LoadSample{CPUBusy: 100, CPUTotal: 1000, NetBytes: 1024}
+	current := LoadSample{CPUBusy: 150, CPUTotal: 1200, NetBytes: 1024 + 20*1024}
+	cpu, net := loadUsage(previous, current, 2*time.Second)
+	assert.Equal(t, 25.0, cpu)
+	assert.Equal(t, 10.0, net)
+
+	cpu, net = loadUsage(current, previous, 2*time.Second)
+	assert.Equal(t, 0.0, cpu)
+	assert.Equal(t, 0.0, net)
+}
@@ -0,0 +1,77 @@
+package shutd
+
+import "time"
+
+// ShutdownSource of ShutdownRequest
+type ShutdownSource string
+
+const (
+	// SourceScheduled when shutdown is scheduled at start time, or snoozed from it
+	SourceScheduled ShutdownSource = "scheduled"
+	// SourceBattery when laptop battery is low
+	SourceBattery ShutdownSource = "battery"
+	// SourceUPS when UPS is on battery
+	SourceUPS ShutdownSource = "ups"
+	// SourceThermal when overheated
+	SourceThermal ShutdownSource = "thermal"
+	// SourceAPI when requested via commands
+	SourceAPI ShutdownSource = "api"
+	// SourcePeer when requested by peer, see Scheduler.ShutdownForPeer
+	SourcePeer ShutdownSource = "peer"
+	// SourceOneOff when shutdown is requested once at explicit time, see Scheduler.ShutdownAt
+	SourceOneOff ShutdownSource = "one-off"
+	// SourceProcess when watched process exits, see Scheduler.ShutdownAfter
+	SourceProcess ShutdownSource = "process"
+	// SourceQuiet when CPU and network are quiet for a while
+	SourceQuiet ShutdownSource = "quiet"
+	// SourceCalendar when scheduled shutdown is shifted after calendar event
+	SourceCalendar ShutdownSource = "calendar"
+	// There is no source for idle user input on purpose, it needs a session API of each desktop,
+	// while SourceQuiet covers the idle computer
+)
+
+// oneOff returns whether the shutdown of the source happens once, so the daily schedule is restored afterwards
+func (source ShutdownSource) oneOff() bool {
+	return source == SourceOneOff || source == SourceProcess || source == SourceCalendar
+}
+
+// ShutdownRequest passed to SchedulerTask, describes why and when the shutdown happens
+type ShutdownRequest struct {
+	// Reason shown to user, empty for scheduled shutdown
+	Reason string
+	Source ShutdownSource
+	// Snoozable is false for emergency shutdowns
+	Snoozable bool
+	// Deadline of the shutdown
+	Deadline time.Time
+}
+
+// suppressible returns whether pause and skip apply to the request, emergency shutdowns are not suppressed
+func (r ShutdownRequest) suppressible() bool {
+	return r.Snoozable
+}
+
+func scheduledRequest() ShutdownRequest {
+	return ShutdownRequest{Source: SourceScheduled, Snoozable: true}
+}
+
+// ShutdownRequest get the pending shutdown request
+func (s *Scheduler) ShutdownRequest() ShutdownRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.shutdownRequest()
+}
+
+func (s *Scheduler) shutdownRequest() ShutdownRequest {
+	r := s.request
+	if s.snoozeLimitReached() {
+		r.Snoozable = false
+	}
+	if s.shutdownJob != nil {
+		r.Deadline = s.scheduledTime()
+		if latest, ok := s.config.Policy.latestShutdown(r.Deadline); ok && !r.Deadline.Before(latest) {
+			r.Snoozable = false
+		}
+	}
+	return r
+}
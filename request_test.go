@@ -0,0 +1,120 @@
+package shutd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduledShutdownRequest(t *testing.T) {
+	requests := make(chan ShutdownRequest, 1)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		requests <- r
+		return nil
+	}
+	config := getConfigWithShutdownTime(time.Now().Add(1 * time.Second).Format("15:04:05"))
+	s, err := getSchedulerWithConfig(t, config, WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+	assert.Equal(t, ShutdownRequest{Source: SourceScheduled, Snoozable: true, Deadline: s.shutdownJob.ScheduledTime()}, s.ShutdownRequest())
+
+	select {
+	case r := <-requests:
+		assert.Equal(t, SourceScheduled, r.Source)
+		assert.True(t, r.Snoozable)
+		assert.Empty(t, r.Reason)
+		assert.WithinDuration(t, time.Now(), r.Deadline, time.Second)
+	case <-time.After(2 * time.Second):
+		t.Fatal("shutdownTask should be called")
+	}
+}
+
+func TestShutdownNowRequest(t *testing.T) {
+	requests := make(chan ShutdownRequest, 1)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		requests <- r
+		return nil
+	}
+	s, err := getSchedulerWithConfig(t, getDefaultConfig(), WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+
+	err = s.ShutdownNow()
+	assert.NoError(t, err)
+	r := <-requests
+	assert.Equal(t, SourceAPI, r.Source)
+	assert.False(t, r.Snoozable)
+
+	e := <-events
+	assert.Equal(t, ShutdownStarted, e.Type)
+	assert.Equal(t, SourceAPI, e.Source)
+	assert.Equal(t, SourceScheduled, s.ShutdownRequest().Source)
+}
+
+func TestShutdownForPeerRequest(t *testing.T) {
+	requests := make(chan ShutdownRequest, 1)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		requests <- r
+		return nil
+	}
+	s, err := getSchedulerWithConfig(t, getDefaultConfig(), WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+
+	err = s.ShutdownForPeer()
+	assert.NoError(t, err)
+	r := <-requests
+	assert.Equal(t, SourcePeer, r.Source)
+	assert.Equal(t, "requested by peer", r.Reason)
+}
+
+func TestSnoozeNotificationForUnsnoozableRequest(t *testing.T) {
+	texts := make(chan string, 1)
+	notifier := NotifierFunc(func(ctx context.Context, title, text string) (bool, error) {
+		texts <- text
+		return true, nil
+	})
+	config := getDefaultConfig()
+	config.Notification.Notifier = "test"
+	s, err := getSchedulerWithConfig(t, config, WithNotifier("test", notifier))
+	assert.NoError(t, err)
+	shutdownTime := s.shutdownJob.ScheduledTime()
+
+	r := ShutdownRequest{Reason: "maintenance", Source: SourceAPI, Deadline: time.Now().Add(5 * time.Minute)}
+	err = newNotificationSnoozeTask()(s, r)
+	assert.NoError(t, err)
+	assert.Equal(t, "Shutdown in 5 minutes (maintenance).", <-texts)
+	assert.Equal(t, shutdownTime, s.shutdownJob.ScheduledTime())
+	assert.Equal(t, 0, s.SnoozeCount())
+}
+
+func TestTriggerKeepsSoonerShutdown(t *testing.T) {
+	s := getScheduler(t)
+	err := s.trigger(ShutdownRequest{Reason: "battery low", Source: SourceBattery, Snoozable: true, Deadline: time.Now().Add(10 * time.Minute)})
+	assert.NoError(t, err)
+	deadline := s.shutdownJob.ScheduledTime()
+
+	err = s.trigger(ShutdownRequest{Reason: "UPS on battery", Source: SourceUPS, Deadline: time.Now().Add(20 * time.Minute)})
+	assert.NoError(t, err)
+	assert.Equal(t, ShutdownRequest{Reason: "battery low", Source: SourceBattery, Deadline: deadline}, s.ShutdownRequest())
+	assert.ErrorIs(t, s.Snooze(), ErrNotSnoozable)
+}
+
+func TestEmergencyShutdownIgnoresPauseAndSkip(t *testing.T) {
+	requests := make(chan ShutdownRequest, 1)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		requests <- r
+		return nil
+	}
+	s, err := getSchedulerWithConfig(t, getDefaultConfig(), WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+	assert.NoError(t, s.Skip())
+	assert.NoError(t, s.Pause())
+
+	err = s.trigger(ShutdownRequest{Reason: "maintenance", Source: SourceAPI, Deadline: time.Now().Add(time.Minute)})
+	assert.NoError(t, err)
+	s.runShutdownJob()
+	r := <-requests
+	assert.Equal(t, SourceAPI, r.Source)
+	assert.True(t, s.Skipped(), "skip is kept for the next scheduled shutdown")
+}
@@ -1,7 +1,10 @@
 package shutd
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-co-op/gocron"
@@ -11,32 +14,70 @@ import (
 const (
 	shutdownTag           = "shutdown"
 	snoozeNotificationTag = "snoozeNotification"
+	// minTriggerDelay of triggered shutdown, so the daily job is not moved to the next day
+	minTriggerDelay = 5 * time.Second
 )
 
-// Config for shutdown scheduler
-type Config struct {
-	SnoozeInterval int
-	StartTime      string
-	Notification   struct {
-		Before   int
-		Duration int
-	}
-}
-
-// Scheduler for auto shutdown the computer
+// Scheduler for auto shutdown the computer, it is safe for concurrent use
 type Scheduler struct {
+	// mu guards the fields below and calls to gocron scheduler of monitors, it is not held while running tasks
+	mu                      sync.Mutex
 	scheduler               *gocron.Scheduler
 	logger                  *logrus.Logger
 	config                  Config
-	shutdownJob             *gocron.Job
-	snoozeNotificationJob   *gocron.Job
+	webhooks                []webhook
+	calendar                calendarFile
+	shutdownJob             *dailyJob
+	snoozeNotificationJob   *dailyJob
 	shutdownTimeChangedChan chan time.Time
 	shutdownTask            SchedulerTask
 	snoozeNotificationTask  SchedulerTask
+	subscribers             []chan Event
+	notifiers               map[string]Notifier
+	snoozeCount             int
+	paused                  bool
+	skipNext                bool
+	waker                   Waker
+	battery                 Battery
+	batteryLow              bool
+	upsOnBatterySince       time.Time
+	upsTriggered            bool
+	thermal                 Thermal
+	overheatedSince         time.Time
+	overheated              bool
+	request                 ShutdownRequest
+	load                    Load
+	loadSample              LoadSample
+	loadSampledAt           time.Time
+	quietSince              time.Time
+	quiet                   bool
+	quietArmed              bool
+	processes               Processes
+	watch                   *ProcessWatch
+	// watchStarted is the start time of the watched process of PID
+	watchStarted uint64
+	// shutdownClock is the clock time of the daily shutdown job, notificationAt is the next run of snooze notification job
+	shutdownClock  time.Duration
+	notificationAt time.Time
+	// now is the clock of the scheduler, jobs are run by timers and gocron only with the real clock
+	now func() time.Time
+	// oneOffReplaces is the daily shutdown time replaced by one-off shutdown
+	oneOffReplaces time.Time
+	pinAttempts    int
+	pinLockedUntil time.Time
+	history        []Event
+	// preShutdownHooks added by AddPreShutdownHook, with the last id given
+	preShutdownHooks []preShutdownHook
+	lastHookID       int
+	// ctx is cancelled on Close, so open notification is closed
+	ctx    context.Context
+	cancel context.CancelFunc
+	tasks  sync.WaitGroup
+	closed bool
 }
 
-// SchedulerTask for scheduler to shutdown or notify for snooze
-type SchedulerTask func(s *Scheduler) error
+// SchedulerTask for scheduler to shutdown or notify for snooze, r describes the pending shutdown
+type SchedulerTask func(s *Scheduler, r ShutdownRequest) error
 
 type option func(*Scheduler)
 
@@ -61,50 +102,233 @@ func WithSnoozeNotificationTask(t SchedulerTask) option {
 	}
 }
 
+// withClock option for Simulate, jobs are not run by timers and gocron and have to be run by the caller at the time of clock
+func withClock(now func() time.Time) option {
+	return func(s *Scheduler) {
+		s.now = now
+	}
+}
+
+// WithNotifier option to register custom notifier by name, it is used when Config.Notification.Notifier matches the name
+func WithNotifier(name string, n Notifier) option {
+	return func(s *Scheduler) {
+		s.notifiers[name] = n
+	}
+}
+
 // NewScheduler to create scheduler to shutdown the computer
 func NewScheduler(config Config, options ...option) (*Scheduler, error) {
 	s := gocron.NewScheduler(time.Local)
-	s.StartAsync()
 	s.TagsUnique()
 
+	ctx, cancel := context.WithCancel(context.Background())
 	scheduler := &Scheduler{
+		ctx:                     ctx,
+		cancel:                  cancel,
 		scheduler:               s,
 		logger:                  logrus.New(),
 		shutdownTimeChangedChan: make(chan time.Time, 1),
 		shutdownTask:            newShutdownTask(),
 		snoozeNotificationTask:  newNotificationSnoozeTask(),
+		notifiers:               defaultNotifiers(),
+		waker:                   defaultWaker(),
+		battery:                 defaultBattery(),
+		thermal:                 defaultThermal(),
+		load:                    defaultLoad(),
+		processes:               defaultProcesses(),
 	}
 	for _, o := range options {
 		o(scheduler)
 	}
+	if scheduler.now == nil {
+		scheduler.now = time.Now
+		s.StartAsync()
+	}
 	err := scheduler.Configure(config)
 	if err != nil {
+		scheduler.Close(context.Background())
 		return nil, err
 	}
 	return scheduler, nil
 }
 
+// Close stops the jobs, cancels open notification and webhook deliveries and waits for running tasks until ctx is done,
+// event channels returned by Subscribe and ShutdownTimeChangedChan are closed. Later calls return ErrClosed
+func (s *Scheduler) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	s.closed = true
+	s.cancel()
+	s.scheduler.Clear()
+	if s.shutdownJob != nil {
+		s.shutdownJob.stop()
+	}
+	if s.snoozeNotificationJob != nil {
+		s.snoozeNotificationJob.stop()
+	}
+	s.mu.Unlock()
+
+	// gocron waits for running monitors, which need the lock to finish
+	done := make(chan struct{})
+	go func() {
+		s.scheduler.Stop()
+		s.tasks.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.subscribers {
+		close(c)
+	}
+	s.subscribers = nil
+	close(s.shutdownTimeChangedChan)
+	s.logger.Info("closed")
+	return err
+}
+
+// beginTask unless closed, the task has to be ended with s.tasks.Done
+func (s *Scheduler) beginTask() bool {
+	if s.closed {
+		return false
+	}
+	s.tasks.Add(1)
+	return true
+}
+
 // Configure scheduler for updated config
 func (s *Scheduler) Configure(config Config) error {
+	err := config.validate()
+	if err != nil {
+		return err
+	}
+	webhooks, err := newWebhooks(config.Webhooks)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	previous := s.config
 	s.config = config
-	s.logger.Infof("config: %+v", config)
+	s.webhooks = webhooks
+	s.logger.WithField("config", fmt.Sprintf("%+v", config.redacted())).Info("configured")
 
-	var err error
-	err = s.scheduleShutdownJob(s.config.StartTime)
+	// monitors are scheduled first, so reschedule keeps requests of monitors which are still triggered
+	err = s.scheduleBatteryJob(previous.Battery)
 	if err != nil {
 		return err
 	}
-	err = s.scheduleSnoozeNotificationJob()
+	err = s.scheduleUPSJob(previous.UPS)
+	if err != nil {
+		return err
+	}
+	err = s.scheduleThermalJob(previous.Thermal)
+	if err != nil {
+		return err
+	}
+	err = s.scheduleQuietJob(previous.Quiet)
 	if err != nil {
 		return err
 	}
+	return s.reschedule()
+}
 
+// reschedule shutdown to the configured start time after config changed, pending one-off shutdown is kept
+// as long as it is still ahead, e.g. one by "shutd at" is not lost when profile is switched. Shutdown triggered by
+// monitors is kept while they are still triggered, so emergency shutdown is not delayed by saving config.
+// While locked by Config.Policy or Config.Protect, notified or snoozed shutdown is not delayed and keeps its snoozes
+func (s *Scheduler) reschedule() error {
+	r, snoozeCount := s.request, s.snoozeCount
+	var previous time.Time
+	if s.shutdownJob != nil {
+		previous = s.scheduledTime()
+	}
+	now := s.now()
+	armed := previous.After(now) && (snoozeCount > 0 || !now.Before(notificationTime(previous, s.config)))
+	err := s.restoreSchedule()
+	if err != nil {
+		return err
+	}
+	if !previous.After(now) {
+		return nil
+	}
+	if r.Source == SourceScheduled && armed && s.config.locked() {
+		// otherwise snoozes are reset and the shutdown is delayed by saving config or switching profile
+		if s.scheduledTime().After(previous) {
+			err = s.scheduleShutdownJob(previous)
+			if err != nil {
+				return err
+			}
+			err = s.scheduleSnoozeNotificationJob()
+			if err != nil {
+				return err
+			}
+		}
+		s.snoozeCount = snoozeCount
+		s.logEntry(shutdownTag).Info("notified or snoozed shutdown is not delayed while locked")
+		return nil
+	}
+	if s.triggered(r.Source) {
+		r.Deadline = previous
+		err = s.trigger(r)
+		if err != nil {
+			return err
+		}
+		s.snoozeCount = snoozeCount
+		s.logEntry(shutdownTag).Info("triggered shutdown is kept")
+		return nil
+	}
+	if !r.Source.oneOff() {
+		return nil
+	}
+	replaces := s.scheduledTime()
+	if s.config.Policy.restrictsDelay() && previous.After(replaces) {
+		s.logEntry(shutdownTag).WithField("source", r.Source).Info("one-off shutdown later than the daily shutdown is dropped by policy")
+		return nil
+	}
+	r.Deadline = previous
+	err = s.shutdownOnce(r, replaces)
+	if err != nil {
+		return err
+	}
+	s.snoozeCount = snoozeCount
+	return nil
+}
+
+// restoreSchedule of shutdown to the configured start time
+func (s *Scheduler) restoreSchedule() error {
+	s.snoozeCount = 0
+	s.request = scheduledRequest()
+	// start time is validated by Configure
+	start, _ := parseClockTime(s.config.StartTime)
+	err := s.scheduleShutdownJob(nextClockTime(s.now(), start))
+	if err != nil {
+		return err
+	}
+	err = s.scheduleSnoozeNotificationJob()
+	if err != nil {
+		return err
+	}
 	s.printJobs()
 	return nil
 }
 
 // Config get config of the scheduler
 func (s *Scheduler) Config() Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.config
 }
 
@@ -113,113 +337,493 @@ func (s *Scheduler) Logger() *logrus.Logger {
 	return s.logger
 }
 
-// ShutdownTimeChangedChan get channel of latest shutdown time
+// SnoozeCount get number of snoozes since last shutdown or configure
+func (s *Scheduler) SnoozeCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snoozeCount
+}
+
+// ShutdownTimeChangedChan get channel of latest shutdown time, it is closed on Close
 func (s *Scheduler) ShutdownTimeChangedChan() chan time.Time {
 	return s.shutdownTimeChangedChan
 }
 
 // ShutdownTime get next shutdown time
 func (s *Scheduler) ShutdownTime() (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.shutdownJob == nil {
-		return time.Time{}, fmt.Errorf("shutdown job is not scheduled")
+		return time.Time{}, ErrNotScheduled
 	}
-	return s.shutdownJob.ScheduledTime(), nil
+	return s.scheduledTime(), nil
 }
 
-// Snooze to delay shutdown time for the computer
+// Snooze to delay shutdown time for the computer, ErrPINRequired is returned when protected by Config.Protect
 func (s *Scheduler) Snooze() error {
-	if s.shutdownJob == nil {
-		return fmt.Errorf("shutdown job is not scheduled")
+	return s.SnoozeWithPIN("")
+}
+
+// SnoozeWithPIN to delay shutdown time for the computer, pin is verified when protected by Config.Protect
+func (s *Scheduler) SnoozeWithPIN(pin string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.snoozedTime()
+	if err != nil {
+		return err
+	}
+	err = s.checkPIN(pin)
+	if err != nil {
+		return err
+	}
+	// shutdown could be snoozed or rescheduled while PIN is compared
+	delayedTime, err := s.snoozedTime()
+	if err != nil {
+		return err
 	}
-	var err error
-	delayedTime := s.shutdownJob.ScheduledTime().Add(time.Duration(s.config.SnoozeInterval) * time.Minute)
 	err = s.scheduleShutdownJob(delayedTime)
 	if err != nil {
 		return err
 	}
+	s.snoozeCount++
 	err = s.scheduleSnoozeNotificationJob()
 	if err != nil {
 		return err
 	}
 
+	s.logEntry(shutdownTag).Info("snoozed")
+	s.emit(Event{Type: Snoozed, ShutdownTime: s.scheduledTime()})
 	s.printJobs()
 	return nil
 }
 
-func (s *Scheduler) scheduleShutdownJob(shutdownTime interface{}) error {
+// CanSnooze returns the error Snooze would return without PIN, e.g. ErrSnoozeLimitReached or ErrNotAllowed by policy
+func (s *Scheduler) CanSnooze() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.snoozedTime()
+	return err
+}
+
+// snoozedTime of the next shutdown, error if it could not be snoozed
+func (s *Scheduler) snoozedTime() (time.Time, error) {
+	if s.closed {
+		return time.Time{}, ErrClosed
+	}
 	if s.shutdownJob == nil {
-		j, err := s.scheduler.Every(1).Day().At(shutdownTime).Tag(shutdownTag).Do(func() {
-			s.Logger().Info("==========================")
-			s.Logger().Info("Shutdown")
-			s.Logger().Info("==========================")
-			err := s.shutdownTask(s)
-			if err != nil {
-				s.logger.Errorf("failed to execute shutdown task: %v", err)
-			}
-		})
-		if err != nil {
-			// not wrapping error to expose implementation details
-			return fmt.Errorf("failed to schedule shutdown job: %v", err)
-		}
-		s.shutdownJob = j
-	} else {
-		_, err := s.scheduler.Job(s.shutdownJob).At(shutdownTime).Update()
-		if err != nil {
-			// not wrapping error to expose implementation details
-			return fmt.Errorf("failed to update scheduled shutdown job: %v", err)
+		return time.Time{}, ErrNotScheduled
+	}
+	if !s.request.Snoozable {
+		return time.Time{}, ErrNotSnoozable
+	}
+	if s.snoozeLimitReached() {
+		return time.Time{}, ErrSnoozeLimitReached
+	}
+	shutdownTime := s.scheduledTime()
+	delayedTime := snoozedShutdownTime(shutdownTime, s.config)
+	if latest, ok := s.config.Policy.latestShutdown(shutdownTime); ok && delayedTime.After(latest) {
+		if !shutdownTime.Before(latest) {
+			return time.Time{}, fmt.Errorf("snooze past %v: %w", latest.Format("15:04"), ErrNotAllowed)
 		}
+		delayedTime = latest
 	}
-	select {
-	case s.shutdownTimeChangedChan <- s.shutdownJob.ScheduledTime():
-	default:
-		// in case no one is waiting for the channel
+	return delayedTime, nil
+}
+
+func (s *Scheduler) snoozeLimitReached() bool {
+	return s.config.MaxSnoozes > 0 && s.snoozeCount >= s.config.MaxSnoozes
+}
+
+// Skip the next shutdown and its snooze notification, later shutdowns are not affected.
+// ErrPINRequired is returned when protected by Config.Protect
+func (s *Scheduler) Skip() error {
+	return s.SkipWithPIN("")
+}
+
+// SkipWithPIN skips the next shutdown, pin is verified when protected by Config.Protect
+func (s *Scheduler) SkipWithPIN(pin string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.canSkip()
+	if err != nil {
+		return err
+	}
+	err = s.checkPIN(pin)
+	if err != nil {
+		return err
+	}
+	// config could be changed or scheduler closed while PIN is compared
+	err = s.canSkip()
+	if err != nil {
+		return err
 	}
+	s.skipNext = true
+	s.logEntry(shutdownTag).Info("skipped next shutdown")
+	s.emit(Event{Type: ShutdownSkipped, ShutdownTime: s.scheduledTime()})
 	return nil
 }
 
-func (s *Scheduler) scheduleSnoozeNotificationJob() error {
+func (s *Scheduler) canSkip() error {
+	if s.closed {
+		return ErrClosed
+	}
 	if s.shutdownJob == nil {
-		return fmt.Errorf("shutdown job is not scheduled")
+		return ErrNotScheduled
+	}
+	if s.config.Policy.DisableSkip {
+		return ErrNotAllowed
 	}
+	return nil
+}
 
-	notifyTime := s.shutdownJob.ScheduledTime().Add(-time.Duration(s.config.Notification.Before) * time.Minute)
-	if s.snoozeNotificationJob == nil {
-		s.scheduler.Every(1).Day().StartAt(notifyTime).Tag(snoozeNotificationTag)
-	} else {
-		s.scheduler.Job(s.snoozeNotificationJob).StartAt(notifyTime)
+// Skipped returns whether the next shutdown is skipped
+func (s *Scheduler) Skipped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.skipNext
+}
+
+// Pause all shutdowns and snooze notifications until Resume, ErrPINRequired is returned when protected by Config.Protect
+func (s *Scheduler) Pause() error {
+	return s.PauseWithPIN("")
+}
+
+// PauseWithPIN pauses all shutdowns until Resume, pin is verified when protected by Config.Protect
+func (s *Scheduler) PauseWithPIN(pin string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.canPause()
+	if err != nil {
+		return err
+	}
+	err = s.checkPIN(pin)
+	if err != nil {
+		return err
+	}
+	// config could be changed or scheduler closed while PIN is compared
+	err = s.canPause()
+	if err != nil {
+		return err
+	}
+	s.paused = true
+	s.logEntry(shutdownTag).Info("paused")
+	s.emit(Event{Type: ShutdownPaused})
+	return nil
+}
+
+func (s *Scheduler) canPause() error {
+	if s.closed {
+		return ErrClosed
+	}
+	if s.config.Policy.DisableSkip {
+		return ErrNotAllowed
 	}
+	return nil
+}
 
-	if time.Now().After(notifyTime) {
-		s.scheduler.StartImmediately()
+// Resume shutdowns and snooze notifications after Pause, PIN is not required as resuming does not delay shutdown
+func (s *Scheduler) Resume() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
 	}
+	s.paused = false
+	s.logEntry(shutdownTag).Info("resumed")
+	s.emit(Event{Type: ShutdownResumed})
+	return nil
+}
 
-	if s.snoozeNotificationJob == nil {
-		j, err := s.scheduler.Do(func() {
-			s.Logger().Info("==========================")
-			s.Logger().Info("Snooze notification")
-			s.Logger().Info("==========================")
-			err := s.snoozeNotificationTask(s)
-			if err != nil {
-				s.logger.Errorf("failed to execute snooze notification task: %v", err)
-			}
-		})
-		if err != nil {
-			// not wrapping error to expose implementation details
-			return fmt.Errorf("failed to schedule snooze notification job: %v", err)
+// Paused returns whether shutdowns are paused
+func (s *Scheduler) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// ShutdownNow shuts down the computer immediately regardless of schedule, e.g. when requested by command
+func (s *Scheduler) ShutdownNow() error {
+	return s.shutdownNow(ShutdownRequest{Source: SourceAPI})
+}
+
+// ShutdownForPeer shuts down the computer immediately as requested by peer, the request has SourcePeer,
+// so pre-shutdown hooks could tell it apart and not forward it back to peers
+func (s *Scheduler) ShutdownForPeer() error {
+	return s.shutdownNow(ShutdownRequest{Reason: "requested by peer", Source: SourcePeer})
+}
+
+func (s *Scheduler) shutdownNow(r ShutdownRequest) error {
+	s.mu.Lock()
+	if !s.beginTask() {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	defer s.tasks.Done()
+	log := s.logEntry(shutdownTag).WithField("source", r.Source)
+	s.mu.Unlock()
+	log.Info("shutdown requested")
+	return s.shutdown(log, r)
+}
+
+// shutdown sets wake alarm, runs pre-shutdown hooks and executes shutdown task for the request, it only logs in dry run.
+// It is called without lock held, so shutdown task could call methods of the scheduler
+func (s *Scheduler) shutdown(log *logrus.Entry, r ShutdownRequest) error {
+	s.mu.Lock()
+	r.Deadline = s.now()
+	delivered := s.emit(Event{Type: ShutdownStarted, ShutdownTime: r.Deadline, Reason: r.Reason, Source: r.Source})
+	webhookWait := s.webhookTimeout(ShutdownStarted)
+	if webhookWait > shutdownWebhookWait {
+		webhookWait = shutdownWebhookWait
+	}
+	s.snoozeCount = 0
+	s.setWakeAlarm(r.Deadline)
+	dryRun := s.config.DryRun
+	hooks := append([]preShutdownHook(nil), s.preShutdownHooks...)
+	s.mu.Unlock()
+	if dryRun {
+		log.Info("dry run: skipped shutdown task")
+		return nil
+	}
+	hooksDone := s.runPreShutdownHooks(log, hooks, r)
+	// webhooks of shutdown_started are posted before network goes down, shutdown is delayed shortly at most
+	if webhookWait > 0 {
+		select {
+		case <-delivered:
+		case <-time.After(webhookWait):
+			log.Warn("webhooks are not delivered before shutdown")
+		case <-s.ctx.Done():
 		}
-		s.snoozeNotificationJob = j
-	} else {
-		_, err := s.scheduler.Update()
-		if err != nil {
-			// not wrapping error to expose implementation details
-			return fmt.Errorf("failed to update scheduled snooze notification job: %v", err)
+	}
+	<-hooksDone
+	err := s.shutdownTask(s, r)
+	if err != nil {
+		log.WithError(err).Error("failed to execute shutdown task")
+		s.mu.Lock()
+		s.emit(Event{Type: ShutdownFailed, ShutdownTime: r.Deadline, Reason: r.Reason, Source: r.Source, Err: err})
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// scheduleShutdownJob daily at the clock time of shutdownTime
+func (s *Scheduler) scheduleShutdownJob(shutdownTime time.Time) error {
+	if s.shutdownJob == nil {
+		s.shutdownJob = newDailyJob(shutdownTag, s.runShutdownJob)
+	}
+	s.shutdownClock = sinceMidnight(shutdownTime) + time.Duration(shutdownTime.Nanosecond())
+	s.shutdownJob.schedule(s.scheduledTime(), s.armed())
+	if !s.closed {
+		select {
+		case s.shutdownTimeChangedChan <- s.scheduledTime():
+		default:
+			// in case no one is waiting for the channel
 		}
 	}
+	s.emit(Event{Type: ShutdownScheduled, ShutdownTime: s.scheduledTime()})
+	return nil
+}
+
+func (s *Scheduler) scheduleSnoozeNotificationJob() error {
+	if s.shutdownJob == nil {
+		return ErrNotScheduled
+	}
+
+	notifyTime := notificationTime(s.scheduledTime(), s.config)
+	if s.snoozeNotificationJob == nil {
+		s.snoozeNotificationJob = newDailyJob(snoozeNotificationTag, s.runSnoozeNotificationJob)
+	}
+	// notification in the past is run immediately
+	s.snoozeNotificationJob.schedule(notifyTime, s.armed())
+	s.notificationAt = laterTime(notifyTime, s.now())
 	return nil
 }
 
+// armed returns whether the daily jobs are run by timers, they are not with the clock of Simulate or once closed
+func (s *Scheduler) armed() bool {
+	return !s.closed && s.scheduler.IsRunning()
+}
+
+// runShutdownJob unless paused, skipped or charging, emergency shutdowns are run regardless and keep the skip
+func (s *Scheduler) runShutdownJob() {
+	calendar := s.checkCalendar(s.now)
+	s.mu.Lock()
+	if !s.beginTask() {
+		s.mu.Unlock()
+		return
+	}
+	defer s.tasks.Done()
+	log := s.logEntry(shutdownTag)
+	log.Info("job triggered")
+	if s.moved() {
+		defer s.restoreAfterRun(log, s.request)
+	}
+	switch {
+	case !s.request.suppressible():
+		if s.paused || s.skipNext {
+			log.Info("emergency shutdown: pause and skip are ignored")
+		}
+	case s.paused:
+		s.mu.Unlock()
+		log.Info("paused: skipped shutdown task")
+		return
+	case s.skipNext:
+		s.skipNext = false
+		s.snoozeCount = 0
+		s.mu.Unlock()
+		log.Info("skipped shutdown task")
+		return
+	}
+	if s.request.Source == SourceScheduled && s.chargingSkip() {
+		s.snoozeCount = 0
+		s.mu.Unlock()
+		log.Info("charging: skipped shutdown task")
+		return
+	}
+	// the job runs at the shutdown time, which is the time calendar is checked for
+	if s.request.Source == SourceScheduled && s.calendarSkip(log, calendar, calendar.ShutdownTime) {
+		s.snoozeCount = 0
+		s.mu.Unlock()
+		return
+	}
+	r := s.request
+	s.mu.Unlock()
+	s.shutdown(log, r)
+}
+
+// scheduledTime of the daily shutdown job, it is the next run at the clock time the same as the job,
+// so it follows the clock of the scheduler
+func (s *Scheduler) scheduledTime() time.Time {
+	return nextClockTime(s.now(), s.shutdownClock)
+}
+
+// moved returns whether the daily job is moved from the configured start time, e.g. by snooze or trigger
+func (s *Scheduler) moved() bool {
+	// start time is validated by Configure
+	start, _ := parseClockTime(s.config.StartTime)
+	return s.request.Source != SourceScheduled || s.shutdownClock != start
+}
+
+// restoreAfterRun restores the daily schedule once the shutdown of r is run, skipped or paused, as snooze and
+// triggers move the daily job, e.g. in dry run or when shutdown failed. Triggers of monitors are reset, so they
+// trigger again if the condition still holds. The daily shutdown replaced by one-off shutdown is skipped if it is still ahead
+func (s *Scheduler) restoreAfterRun(log *logrus.Entry, r ShutdownRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the request is replaced meanwhile, e.g. shifted by calendar or restored by Configure
+	if s.closed || s.request != r {
+		return
+	}
+	err := s.restoreSchedule()
+	if err != nil {
+		log.WithError(err).Error("failed to restore shutdown schedule after shutdown")
+		return
+	}
+	s.resetTriggers()
+	if r.Source.oneOff() && !s.scheduledTime().After(s.oneOffReplaces) {
+		s.skipNext = true
+		log.Info("one-off shutdown is over: skipped the daily shutdown it replaced")
+		return
+	}
+	log.Info("shutdown is over: shutdown schedule restored")
+}
+
+// triggered returns whether the monitor of source is still triggered
+func (s *Scheduler) triggered(source ShutdownSource) bool {
+	switch source {
+	case SourceBattery:
+		return s.batteryLow
+	case SourceUPS:
+		return s.upsTriggered
+	case SourceThermal:
+		return s.overheated
+	case SourceQuiet:
+		return s.quiet
+	}
+	return false
+}
+
+// resetTriggers of battery, UPS, thermal and quiet monitors once the schedule is restored without their requests
+func (s *Scheduler) resetTriggers() {
+	s.batteryLow = false
+	s.upsTriggered = false
+	s.overheatedSince = time.Time{}
+	s.overheated = false
+	s.quietSince = time.Time{}
+	s.quiet = false
+	s.quietArmed = false
+}
+
+// runSnoozeNotificationJob unless paused, skipped or charging while not an emergency, the task is run without lock held as it waits for user
+func (s *Scheduler) runSnoozeNotificationJob() {
+	calendar := s.checkCalendar(s.scheduledTime)
+	s.mu.Lock()
+	if !s.beginTask() {
+		s.mu.Unlock()
+		return
+	}
+	defer s.tasks.Done()
+	log := s.logEntry(snoozeNotificationTag)
+	log.Info("job triggered")
+	if s.request.suppressible() && (s.paused || s.skipNext) {
+		s.mu.Unlock()
+		log.Info("shutdown is paused or skipped: skipped snooze notification task")
+		return
+	}
+	if s.request.Source == SourceScheduled && s.chargingSkip() {
+		s.mu.Unlock()
+		log.Info("charging: skipped snooze notification task")
+		return
+	}
+	if s.request.Source == SourceScheduled && s.calendarSkip(log, calendar, s.scheduledTime()) {
+		s.mu.Unlock()
+		return
+	}
+	r := s.shutdownRequest()
+	s.mu.Unlock()
+	err := s.snoozeNotificationTask(s, r)
+	if err != nil {
+		log.WithError(err).Error("failed to execute snooze notification task")
+	}
+}
+
 func (s *Scheduler) printJobs() {
+	for _, j := range []*dailyJob{s.shutdownJob, s.snoozeNotificationJob} {
+		if j == nil {
+			continue
+		}
+		s.logger.WithFields(logrus.Fields{
+			"job":           strings.Join(j.Tags(), ","),
+			"scheduledTime": j.ScheduledTime().Format(time.RFC3339),
+			"snoozeCount":   s.snoozeCount,
+		}).Info("job scheduled")
+	}
 	for _, j := range s.scheduler.Jobs() {
-		s.logger.Infof("job: %v, scheduled: %v (%v), nextRun: %v (%v), count: %v", j.Tags(), j.ScheduledTime().Format("15:04"), j.ScheduledTime(), j.NextRun().Format("15:04"), j.NextRun(), j.RunCount())
+		s.logger.WithFields(logrus.Fields{
+			"job":     strings.Join(j.Tags(), ","),
+			"nextRun": j.NextRun().Format(time.RFC3339),
+		}).Info("job scheduled")
+	}
+}
+
+// logEntry with job and the current shutdown state as fields
+func (s *Scheduler) logEntry(job string) *logrus.Entry {
+	fields := logrus.Fields{
+		"job":         job,
+		"snoozeCount": s.snoozeCount,
+	}
+	if s.shutdownJob != nil {
+		fields["scheduledTime"] = s.scheduledTime().Format(time.RFC3339)
+	}
+	if s.paused {
+		fields["paused"] = true
+	}
+	if s.skipNext {
+		fields["skipped"] = true
+	}
+	if s.request.Source != SourceScheduled {
+		fields["source"] = s.request.Source
+		fields["reason"] = s.request.Reason
 	}
+	return s.logger.WithFields(fields)
 }
@@ -1,10 +1,13 @@
 package shutd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 )
@@ -12,13 +15,10 @@ import (
 func getDefaultConfig() Config {
 	return Config{
 		StartTime:      "00:00",
-		SnoozeInterval: 15,
-		Notification: struct {
-			Before   int
-			Duration int
-		}{
-			Before:   10,
-			Duration: 10,
+		SnoozeInterval: 15 * time.Minute,
+		Notification: NotificationConfig{
+			Before:   10 * time.Minute,
+			Duration: 10 * time.Minute,
 		},
 	}
 }
@@ -37,13 +37,21 @@ func getScheduler(t *testing.T) *Scheduler {
 }
 
 func getSchedulerWithConfig(t *testing.T, config Config, options ...option) (*Scheduler, error) {
-	shutdownTask := func(s *Scheduler) error { return nil }
-	snoozeNotificationTask := func(s *Scheduler) error { return nil }
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error { return nil }
+	snoozeNotificationTask := func(s *Scheduler, r ShutdownRequest) error { return nil }
 	options = append([]option{WithShutdownTask(shutdownTask), WithSnoozeNotificationTask(snoozeNotificationTask)}, options...)
 	s, err := NewScheduler(config, options...)
 	if err != nil {
 		return nil, err
 	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := s.Close(ctx)
+		if err != nil && !errors.Is(err, ErrClosed) {
+			t.Errorf("failed to close scheduler: %v", err)
+		}
+	})
 
 	assert.Equal(t, s.Config(), config)
 	return s, nil
@@ -68,6 +76,11 @@ func TestSnooze(t *testing.T) {
 
 	assert.Equal(t, s.shutdownJob.ScheduledTime().Format("15:04"), "00:15")
 	assert.Equal(t, s.snoozeNotificationJob.ScheduledTime().Format("15:04"), "00:05")
+	assert.Equal(t, 1, s.SnoozeCount())
+
+	err = s.Configure(getDefaultConfig())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, s.SnoozeCount())
 }
 
 func TestConfigureWillUpdateJobTime(t *testing.T) {
@@ -77,13 +90,10 @@ func TestConfigureWillUpdateJobTime(t *testing.T) {
 
 	err := s.Configure(Config{
 		StartTime:      "02:00",
-		SnoozeInterval: 15,
-		Notification: struct {
-			Before   int
-			Duration int
-		}{
-			Before:   10,
-			Duration: 10,
+		SnoozeInterval: 15 * time.Minute,
+		Notification: NotificationConfig{
+			Before:   10 * time.Minute,
+			Duration: 10 * time.Minute,
 		},
 	})
 	assert.NoError(t, err)
@@ -96,12 +106,12 @@ func TestConfigureWithInvalidTimeFormat(t *testing.T) {
 	s := getScheduler(t)
 	config := getConfigWithShutdownTime("32:00")
 	err := s.Configure(config)
-	assert.EqualError(t, err, "failed to update scheduled shutdown job: the given time format is not supported")
+	assert.EqualError(t, err, `invalid startTime "32:00": the given time format is not supported`)
 }
 
 func TestNewSchedulerWithInvalidTimeFormatConfig(t *testing.T) {
 	_, err := getSchedulerWithConfig(t, getConfigWithShutdownTime("32:00"))
-	assert.EqualError(t, err, "failed to schedule shutdown job: the given time format is not supported")
+	assert.EqualError(t, err, `invalid startTime "32:00": the given time format is not supported`)
 }
 
 func TestShutdownTime(t *testing.T) {
@@ -115,26 +125,26 @@ func TestShutdownTimeWithoutShutdownJob(t *testing.T) {
 	s := getScheduler(t)
 	s.shutdownJob = nil
 	_, err := s.ShutdownTime()
-	assert.EqualError(t, err, "shutdown job is not scheduled")
+	assert.ErrorIs(t, err, ErrNotScheduled)
 }
 
 func TestSchedulingOfSnoozeNotificationWithoutShutdownJob(t *testing.T) {
 	s := getScheduler(t)
 	s.shutdownJob = nil
 	err := s.scheduleSnoozeNotificationJob()
-	assert.EqualError(t, err, "shutdown job is not scheduled")
+	assert.ErrorIs(t, err, ErrNotScheduled)
 }
 
 func TestSnoozeWithoutShutdownJob(t *testing.T) {
 	s := getScheduler(t)
 	s.shutdownJob = nil
 	err := s.Snooze()
-	assert.EqualError(t, err, "shutdown job is not scheduled")
+	assert.ErrorIs(t, err, ErrNotScheduled)
 }
 
 func TestSnoozeNotificationStillTriggerBeforeShutdown(t *testing.T) {
 	called := make(chan bool)
-	snoozeNotificationTask := func(s *Scheduler) error {
+	snoozeNotificationTask := func(s *Scheduler, r ShutdownRequest) error {
 		called <- true
 		return nil
 	}
@@ -152,7 +162,7 @@ func TestSnoozeNotificationStillTriggerBeforeShutdown(t *testing.T) {
 func TestSnoozeNotificationCanTriggeredAfterRescheduled(t *testing.T) {
 	times := 0
 	called := make(chan bool)
-	snoozeNotificationTask := func(s *Scheduler) error {
+	snoozeNotificationTask := func(s *Scheduler, r ShutdownRequest) error {
 		times++
 		called <- true
 		return nil
@@ -200,7 +210,7 @@ func TestSnoozeNotificationTaskWithError(t *testing.T) {
 	testLogger, hook := test.NewNullLogger()
 
 	called := make(chan bool)
-	snoozeNotificationTask := func(s *Scheduler) error {
+	snoozeNotificationTask := func(s *Scheduler, r ShutdownRequest) error {
 		called <- true
 		return fmt.Errorf("testing error")
 	}
@@ -211,7 +221,9 @@ func TestSnoozeNotificationTaskWithError(t *testing.T) {
 	select {
 	case <-called:
 		time.Sleep(100 * time.Millisecond)
-		assert.Equal(t, hook.LastEntry().Message, "failed to execute snooze notification task: testing error")
+		assert.Equal(t, hook.LastEntry().Message, "failed to execute snooze notification task")
+		assert.EqualError(t, hook.LastEntry().Data[logrus.ErrorKey].(error), "testing error")
+		assert.Equal(t, hook.LastEntry().Data["job"], "snoozeNotification")
 	case <-time.After(2 * time.Second):
 		t.Fatal("snoozeNotificationTask should be called")
 	}
@@ -220,7 +232,7 @@ func TestSnoozeNotificationTaskWithError(t *testing.T) {
 func TestShutdownTaskTriggered(t *testing.T) {
 
 	called := make(chan bool)
-	shutdownTask := func(s *Scheduler) error {
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
 		called <- true
 		return nil
 	}
@@ -239,7 +251,7 @@ func TestShutdownTaskWithError(t *testing.T) {
 	testLogger, hook := test.NewNullLogger()
 
 	called := make(chan bool)
-	shutdownTask := func(s *Scheduler) error {
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
 		called <- true
 		return fmt.Errorf("testing error")
 	}
@@ -250,7 +262,9 @@ func TestShutdownTaskWithError(t *testing.T) {
 	select {
 	case <-called:
 		time.Sleep(100 * time.Millisecond)
-		assert.Equal(t, hook.LastEntry().Message, "failed to execute shutdown task: testing error")
+		assert.Equal(t, hook.LastEntry().Message, "failed to execute shutdown task")
+		assert.EqualError(t, hook.LastEntry().Data[logrus.ErrorKey].(error), "testing error")
+		assert.Equal(t, hook.LastEntry().Data["job"], "shutdown")
 	case <-time.After(2 * time.Second):
 		t.Fatal("shutdownTask should be called")
 	}
@@ -277,3 +291,154 @@ func TestShutdownTimeChangedChanShouldGetLatestShutdownTime(t *testing.T) {
 		t.Fatal("shutdownTimeChangedChan should have the latest shutdown time value")
 	}
 }
+
+func TestShutdownTaskNotCalledInDryRun(t *testing.T) {
+	called := make(chan bool, 1)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		called <- true
+		return nil
+	}
+	config := getConfigWithShutdownTime(time.Now().Add(1 * time.Second).Format("15:04:05"))
+	config.DryRun = true
+	s, err := getSchedulerWithConfig(t, config, WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+
+	select {
+	case e := <-events:
+		assert.Equal(t, ShutdownStarted, e.Type)
+		assert.True(t, e.DryRun)
+	case <-time.After(2 * time.Second):
+		t.Fatal("ShutdownStarted event should be emitted")
+	}
+	select {
+	case <-called:
+		t.Fatal("shutdownTask should not be called in dry run")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestShutdownFailedEventEmitted(t *testing.T) {
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		return fmt.Errorf("testing error")
+	}
+	config := getConfigWithShutdownTime(time.Now().Add(1 * time.Second).Format("15:04:05"))
+	s, err := getSchedulerWithConfig(t, config, WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+
+	var types []EventType
+	timeout := time.After(2 * time.Second)
+	for len(types) < 2 {
+		select {
+		case e := <-events:
+			types = append(types, e.Type)
+			if e.Type == ShutdownFailed {
+				assert.EqualError(t, e.Err, "testing error")
+			}
+		case <-timeout:
+			t.Fatalf("ShutdownStarted and ShutdownFailed events should be emitted, got %v", types)
+		}
+	}
+	assert.Equal(t, []EventType{ShutdownStarted, ShutdownFailed}, types)
+}
+
+func TestSnoozeLogFields(t *testing.T) {
+	testLogger, hook := test.NewNullLogger()
+	s, err := getSchedulerWithConfig(t, getDefaultConfig(), WithLogger(testLogger))
+	assert.NoError(t, err)
+
+	err = s.Snooze()
+	assert.NoError(t, err)
+
+	var entry *logrus.Entry
+	for _, e := range hook.AllEntries() {
+		if e.Message == "snoozed" {
+			entry = e
+		}
+	}
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, "shutdown", entry.Data["job"])
+		assert.Equal(t, 1, entry.Data["snoozeCount"])
+		assert.Equal(t, s.shutdownJob.ScheduledTime().Format(time.RFC3339), entry.Data["scheduledTime"])
+	}
+}
+
+func TestSkipNextShutdown(t *testing.T) {
+	called := make(chan bool, 1)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		called <- true
+		return nil
+	}
+	config := getConfigWithShutdownTime(time.Now().Add(1 * time.Second).Format("15:04:05"))
+	s, err := getSchedulerWithConfig(t, config, WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+
+	err = s.Skip()
+	assert.NoError(t, err)
+	assert.True(t, s.Skipped())
+
+	select {
+	case <-called:
+		t.Fatal("shutdownTask should be skipped")
+	case <-time.After(2 * time.Second):
+	}
+	assert.False(t, s.Skipped())
+}
+
+func TestSkipWithoutShutdownJob(t *testing.T) {
+	s := getScheduler(t)
+	s.shutdownJob = nil
+	err := s.Skip()
+	assert.ErrorIs(t, err, ErrNotScheduled)
+}
+
+func TestPauseAndResume(t *testing.T) {
+	called := make(chan bool, 1)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		called <- true
+		return nil
+	}
+	config := getConfigWithShutdownTime(time.Now().Add(1 * time.Second).Format("15:04:05"))
+	s, err := getSchedulerWithConfig(t, config, WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+
+	assert.NoError(t, s.Pause())
+	assert.True(t, s.Paused())
+	assert.Equal(t, ShutdownPaused, (<-events).Type)
+
+	select {
+	case <-called:
+		t.Fatal("shutdownTask should not be called when paused")
+	case <-time.After(2 * time.Second):
+	}
+
+	assert.NoError(t, s.Resume())
+	assert.False(t, s.Paused())
+	assert.Equal(t, ShutdownResumed, (<-events).Type)
+}
+
+func TestShutdownNow(t *testing.T) {
+	called := 0
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		called++
+		return nil
+	}
+	s, err := getSchedulerWithConfig(t, getDefaultConfig(), WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+
+	err = s.ShutdownNow()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, called)
+	assert.Equal(t, ShutdownStarted, (<-events).Type)
+
+	config := getDefaultConfig()
+	config.DryRun = true
+	err = s.Configure(config)
+	assert.NoError(t, err)
+	err = s.ShutdownNow()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, called)
+}
@@ -0,0 +1,15 @@
+//go:build darwin
+
+package shutd
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+func execShutdown() (err error) {
+	if err := exec.Command("osascript", "-e", `tell app "System Events" to shut down`).Run(); err != nil {
+		return fmt.Errorf("failed to initiate shutdown: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build !windows && !darwin
+
+package shutd
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+func execShutdown() (err error) {
+	// systemd allows active session user to power off without root
+	if err := exec.Command("systemctl", "poweroff").Run(); err != nil {
+		return fmt.Errorf("failed to initiate shutdown: %w", err)
+	}
+	return nil
+}
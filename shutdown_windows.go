@@ -0,0 +1,15 @@
+//go:build windows
+
+package shutd
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+func execShutdown() (err error) {
+	if err := exec.Command("cmd", "/C", "shutdown", "/t", "0", "/s", "/hybrid").Run(); err != nil {
+		return fmt.Errorf("failed to initiate shutdown: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package shutd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SimulatedEventType of SimulatedEvent
+type SimulatedEventType string
+
+const (
+	// SimulatedNotification when snooze notification is shown
+	SimulatedNotification SimulatedEventType = "notification"
+	// SimulatedSnooze when user snoozed the shutdown
+	SimulatedSnooze SimulatedEventType = "snooze"
+	// SimulatedShutdown when the computer is shut down
+	SimulatedShutdown SimulatedEventType = "shutdown"
+	// SimulatedSkip when the shutdown is skipped by skip dates or calendar
+	SimulatedSkip SimulatedEventType = "skip"
+)
+
+// SimulatedEvent happened in the simulated timeline
+type SimulatedEvent struct {
+	Type         SimulatedEventType
+	Time         time.Time
+	ShutdownTime time.Time
+	// Reason of skipped or shifted shutdown
+	Reason string
+}
+
+// SimulateOptions for Simulate
+type SimulateOptions struct {
+	// From is the fake clock time that the simulation starts at
+	From time.Time
+	// Days to simulate
+	Days int
+	// SnoozeAt are clock times (e.g. "00:50") that user snoozes at, applied on each simulated night
+	SnoozeAt []string
+}
+
+// Simulate the timeline of notifications, snoozes and shutdowns for config without waiting for the real time.
+// It drives a Scheduler with a fake clock, so the timeline is the same as the scheduler would do.
+// Monitors, webhooks, wake alarm and PIN are not simulated
+func Simulate(config Config, opts SimulateOptions) ([]SimulatedEvent, error) {
+	snoozeAt := make([]time.Duration, 0, len(opts.SnoozeAt))
+	for _, t := range opts.SnoozeAt {
+		d, err := parseClockTime(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snooze time %q: %w", t, err)
+		}
+		snoozeAt = append(snoozeAt, d)
+	}
+
+	sim := &simulation{now: opts.From, snoozedUntil: opts.From}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	s, err := NewScheduler(simulatedConfig(config),
+		withClock(func() time.Time { return sim.now }),
+		WithLogger(logger),
+		WithShutdownTask(sim.shutdown),
+		WithSnoozeNotificationTask(sim.notify),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close(context.Background())
+
+	end := opts.From.AddDate(0, 0, opts.Days)
+	for {
+		s.mu.Lock()
+		notifyTime, shutdownTime := s.notificationAt, s.scheduledTime()
+		s.mu.Unlock()
+		var snoozeTime time.Time
+		for _, d := range snoozeAt {
+			t := nextClockTime(sim.snoozedUntil, d)
+			if snoozeTime.IsZero() || t.Before(snoozeTime) {
+				snoozeTime = t
+			}
+		}
+
+		// jobs due at the same time run in the order of notification, shutdown and snooze by user
+		switch {
+		case !notifyTime.After(shutdownTime) && (snoozeTime.IsZero() || !notifyTime.After(snoozeTime)):
+			if !notifyTime.Before(end) {
+				return sim.events, nil
+			}
+			sim.now = notifyTime
+			s.mu.Lock()
+			// the same as gocron, the job is run daily unless rescheduled by the run
+			s.notificationAt = notifyTime.AddDate(0, 0, 1)
+			s.mu.Unlock()
+			s.runSnoozeNotificationJob()
+		case snoozeTime.IsZero() || !shutdownTime.After(snoozeTime):
+			if !shutdownTime.Before(end) {
+				return sim.events, nil
+			}
+			sim.now = shutdownTime
+			sim.shutdownRun = false
+			s.runShutdownJob()
+			if sim.shutdownRun {
+				// user could not snooze anymore in the night once the computer is shut down
+				sim.snoozedUntil = nightOf(shutdownTime).AddDate(0, 0, 1).Add(12 * time.Hour)
+			}
+			if reason, ok := s.SkipReason(shutdownTime); ok && !sim.shutdownRun {
+				sim.events = append(sim.events, SimulatedEvent{Type: SimulatedSkip, Time: shutdownTime, ShutdownTime: shutdownTime, Reason: reason})
+			}
+		default:
+			if !snoozeTime.Before(end) {
+				return sim.events, nil
+			}
+			sim.now, sim.snoozedUntil = snoozeTime, snoozeTime
+			err = s.Snooze()
+			if errors.Is(err, ErrSnoozeLimitReached) || errors.Is(err, ErrNotSnoozable) || errors.Is(err, ErrNotAllowed) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			shutdownTime, _ = s.ShutdownTime()
+			sim.events = append(sim.events, SimulatedEvent{Type: SimulatedSnooze, Time: snoozeTime, ShutdownTime: shutdownTime})
+		}
+	}
+}
+
+// simulatedConfig of config without monitors, webhooks, wake alarm and PIN, so simulation has no side effects
+func simulatedConfig(config Config) Config {
+	policy := config.Policy
+	policy.Protect = ProtectConfig{}
+	return Config{
+		SnoozeInterval: config.SnoozeInterval,
+		StartTime:      config.StartTime,
+		MaxSnoozes:     config.MaxSnoozes,
+		Notification:   config.Notification,
+		SkipDates:      config.SkipDates,
+		Calendar:       config.Calendar,
+		Policy:         policy,
+	}
+}
+
+// simulation records events of tasks run by the scheduler at the fake clock
+type simulation struct {
+	now    time.Time
+	events []SimulatedEvent
+	// snoozedUntil is the time of the last snooze by user, or when the user could snooze again after shutdown
+	snoozedUntil time.Time
+	shutdownRun  bool
+}
+
+func (sim *simulation) notify(s *Scheduler, r ShutdownRequest) error {
+	sim.events = append(sim.events, SimulatedEvent{Type: SimulatedNotification, Time: sim.now, ShutdownTime: r.Deadline})
+	return nil
+}
+
+func (sim *simulation) shutdown(s *Scheduler, r ShutdownRequest) error {
+	sim.shutdownRun = true
+	sim.events = append(sim.events, SimulatedEvent{Type: SimulatedShutdown, Time: sim.now, ShutdownTime: r.Deadline, Reason: r.Reason})
+	return nil
+}
+
+// notificationTime of snooze notification for the shutdown time
+func notificationTime(shutdownTime time.Time, config Config) time.Time {
+	return shutdownTime.Add(-config.Notification.Before)
+}
+
+// snoozedShutdownTime after snoozing the shutdown time
+func snoozedShutdownTime(shutdownTime time.Time, config Config) time.Time {
+	return shutdownTime.Add(config.SnoozeInterval)
+}
+
+// parseClockTime parses clock time in the same formats as the scheduler, returns duration since midnight
+func parseClockTime(t string) (time.Duration, error) {
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		parsed, err := time.Parse(layout, t)
+		if err == nil {
+			return sinceMidnight(parsed), nil
+		}
+	}
+	return 0, ErrInvalidTime
+}
+
+// NextClockTime returns the next time after now at the clock time, e.g. "07:30" or "07:30:15"
+func NextClockTime(now time.Time, clock string) (time.Time, error) {
+	c, err := parseClockTime(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return nextClockTime(now, c), nil
+}
+
+// nextClockTime returns the next time after now at the clock time
+func nextClockTime(now time.Time, clock time.Duration) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	t := midnight.Add(clock)
+	if !t.After(now) {
+		t = midnight.AddDate(0, 0, 1).Add(clock)
+	}
+	return t
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+func laterTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
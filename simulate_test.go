@@ -0,0 +1,151 @@
+package shutd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func formatSimulatedEvents(events []SimulatedEvent) []string {
+	var lines []string
+	for _, e := range events {
+		lines = append(lines, e.Time.Format("01-02 15:04")+" "+string(e.Type)+" "+e.ShutdownTime.Format("15:04"))
+	}
+	return lines
+}
+
+func TestSimulate(t *testing.T) {
+	config := getConfigWithShutdownTime("01:00")
+	events, err := Simulate(config, SimulateOptions{
+		From: time.Date(2026, 10, 19, 20, 0, 0, 0, time.Local),
+		Days: 2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"10-20 00:50 notification 01:00",
+		"10-20 01:00 shutdown 01:00",
+		"10-21 00:50 notification 01:00",
+		"10-21 01:00 shutdown 01:00",
+	}, formatSimulatedEvents(events))
+}
+
+func TestSimulateWithSnoozes(t *testing.T) {
+	config := getConfigWithShutdownTime("01:00")
+	events, err := Simulate(config, SimulateOptions{
+		From:     time.Date(2026, 10, 19, 20, 0, 0, 0, time.Local),
+		Days:     1,
+		SnoozeAt: []string{"01:05", "00:50"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"10-20 00:50 notification 01:00",
+		"10-20 00:50 snooze 01:15",
+		"10-20 01:05 notification 01:15",
+		"10-20 01:05 snooze 01:30",
+		"10-20 01:20 notification 01:30",
+		"10-20 01:30 shutdown 01:30",
+	}, formatSimulatedEvents(events))
+}
+
+func TestSimulateWithSnoozeBeforeNotification(t *testing.T) {
+	config := getConfigWithShutdownTime("01:00")
+	events, err := Simulate(config, SimulateOptions{
+		From:     time.Date(2026, 10, 19, 20, 0, 0, 0, time.Local),
+		Days:     1,
+		SnoozeAt: []string{"23:00"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"10-19 23:00 snooze 01:15",
+		"10-20 01:05 notification 01:15",
+		"10-20 01:15 shutdown 01:15",
+	}, formatSimulatedEvents(events))
+}
+
+func TestSimulateWithInvalidTimeFormat(t *testing.T) {
+	_, err := Simulate(getConfigWithShutdownTime("32:00"), SimulateOptions{Days: 1})
+	assert.EqualError(t, err, `invalid startTime "32:00": the given time format is not supported`)
+
+	_, err = Simulate(getDefaultConfig(), SimulateOptions{Days: 1, SnoozeAt: []string{"x"}})
+	assert.EqualError(t, err, `invalid snooze time "x": the given time format is not supported`)
+	assert.ErrorIs(t, err, ErrInvalidTime)
+}
+
+func TestSimulateWithMaxSnoozes(t *testing.T) {
+	config := getConfigWithShutdownTime("01:00")
+	config.MaxSnoozes = 1
+	events, err := Simulate(config, SimulateOptions{
+		From:     time.Date(2026, 10, 19, 20, 0, 0, 0, time.Local),
+		Days:     1,
+		SnoozeAt: []string{"01:05", "00:50"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"10-20 00:50 notification 01:00",
+		"10-20 00:50 snooze 01:15",
+		"10-20 01:05 notification 01:15",
+		"10-20 01:15 shutdown 01:15",
+	}, formatSimulatedEvents(events))
+}
+
+func TestSimulateWithCalendar(t *testing.T) {
+	config := getConfigWithShutdownTime("01:00")
+	config.SkipDates = []string{"2026-10-20"}
+	config.Calendar.ICS = writeICS(t, "SUMMARY:Release night\nDTSTART:20261021T230000\nDTEND:20261022T023000\n")
+	events, err := Simulate(config, SimulateOptions{
+		From: time.Date(2026, 10, 19, 20, 0, 0, 0, time.Local),
+		Days: 4,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"10-20 00:50 notification 01:00",
+		"10-20 01:00 shutdown 01:00",
+		"10-21 01:00 skip 01:00",
+		"10-22 02:20 notification 02:30",
+		"10-22 02:30 shutdown 02:30",
+		"10-23 00:50 notification 01:00",
+		"10-23 01:00 shutdown 01:00",
+	}, formatSimulatedEvents(events))
+	assert.Equal(t, "skip date 2026-10-20", events[2].Reason)
+	assert.Equal(t, `shifted after calendar event "Release night"`, events[4].Reason)
+}
+
+func TestSimulateRestoresStartTimeAfterSnoozedNight(t *testing.T) {
+	config := getConfigWithShutdownTime("01:00")
+	events, err := Simulate(config, SimulateOptions{
+		From:     time.Date(2026, 10, 19, 20, 0, 0, 0, time.Local),
+		Days:     2,
+		SnoozeAt: []string{"00:50"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"10-20 00:50 notification 01:00",
+		"10-20 00:50 snooze 01:15",
+		"10-20 01:05 notification 01:15",
+		"10-20 01:15 shutdown 01:15",
+		"10-21 00:50 notification 01:00",
+		"10-21 00:50 snooze 01:15",
+		"10-21 01:05 notification 01:15",
+		"10-21 01:15 shutdown 01:15",
+	}, formatSimulatedEvents(events))
+}
+
+func TestSimulateWithPolicy(t *testing.T) {
+	config := getConfigWithShutdownTime("01:00")
+	config.Policy = Policy{LatestStartTime: "01:20"}
+	events, err := Simulate(config, SimulateOptions{
+		From:     time.Date(2026, 10, 19, 20, 0, 0, 0, time.Local),
+		Days:     1,
+		SnoozeAt: []string{"00:50", "01:05", "01:12"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"10-20 00:50 notification 01:00",
+		"10-20 00:50 snooze 01:15",
+		"10-20 01:05 notification 01:15",
+		"10-20 01:05 snooze 01:20",
+		"10-20 01:10 notification 01:20",
+		"10-20 01:20 shutdown 01:20",
+	}, formatSimulatedEvents(events))
+}
@@ -4,31 +4,77 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"time"
 )
 
 func newNotificationSnoozeTask() SchedulerTask {
-	return func(s *Scheduler) error {
-		shutdownTime, err := s.ShutdownTime()
+	return func(s *Scheduler, r ShutdownRequest) error {
+		config := s.Config()
+		shutdownTime := r.Deadline
+		title := fmt.Sprintf("Shutd - Shutdown at %v", shutdownTime.Format("15:04"))
+		if config.DryRun {
+			title += " (dry run)"
+		}
+		text := fmt.Sprintf("Shutdown in %.0f minutes", shutdownTime.Sub(s.now()).Minutes())
+		if r.Reason != "" {
+			text += fmt.Sprintf(" (%v)", r.Reason)
+		}
+		if !r.Snoozable {
+			text += "."
+		} else {
+			text += fmt.Sprintf(", snooze for %v?", formatMinutes(config.SnoozeInterval))
+		}
+		if wakeTime, ok := s.WakeTime(); ok {
+			text += fmt.Sprintf(" Wakes at %v", wakeTime.Format("15:04"))
+		}
+
+		notifier, err := s.notifier(config.Notification.Notifier)
 		if err != nil {
 			return err
 		}
-		title := fmt.Sprintf("Shutd - Shutdown at %v", shutdownTime.Format("15:04"))
-		text := fmt.Sprintf("Shutdown in %.0f minutes, snooze for %v minutes?", time.Until(shutdownTime).Minutes(), s.Config().SnoozeInterval)
-
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Config().Notification.Duration)*time.Minute)
+		s.mu.Lock()
+		s.emit(Event{Type: NotificationShown, ShutdownTime: shutdownTime})
+		log := s.logEntry(snoozeNotificationTag)
+		s.mu.Unlock()
+		ctx, cancel := context.WithTimeout(s.ctx, config.Notification.Duration)
 		defer cancel()
-		yes, err := question(ctx, title, text)
+		yes, err := notifier.Question(ctx, title, text)
 		if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
 			return fmt.Errorf("failed to display snooze notification: %v", err)
 		}
-		s.Logger().Infof("snooze is required: %v", yes)
-		if yes {
-			err := s.Snooze()
-			if err != nil {
-				return err
-			}
+		log.WithField("snooze", yes).Info("snooze notification answered")
+		if yes && r.Snoozable {
+			return s.snoozeAnswered(ctx, notifier, title)
 		}
 		return nil
 	}
 }
+
+// snoozeAnswered snoozes once notification is answered, PIN is asked until it is correct when protected
+func (s *Scheduler) snoozeAnswered(ctx context.Context, notifier Notifier, title string) error {
+	if !s.Protected() {
+		return s.Snooze()
+	}
+	asker, ok := notifier.(PINAsker)
+	if !ok {
+		return fmt.Errorf("notifier could not ask for PIN: %w", ErrPINRequired)
+	}
+	text := "Enter PIN to snooze"
+	for {
+		pin, err := asker.AskPIN(ctx, title, text)
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to ask for PIN: %v", err)
+		}
+		// cancelled by user
+		if pin == "" {
+			return nil
+		}
+		err = s.SnoozeWithPIN(pin)
+		if !errors.Is(err, ErrWrongPIN) {
+			return err
+		}
+		text = "Wrong PIN, enter PIN to snooze"
+	}
+}
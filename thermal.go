@@ -0,0 +1,121 @@
+package shutd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	thermalTag                 = "thermal"
+	defaultThermalPollInterval = 10 * time.Second
+	defaultThermalFor          = time.Minute
+	defaultThermalNotice       = time.Minute
+)
+
+// ThermalConfig for emergency shutdown when overheated
+type ThermalConfig struct {
+	// ShutdownAbove temperature in degree Celsius, 0 to disable
+	ShutdownAbove float64
+	// For how long the temperature is above threshold before shutdown, defaults to 1m
+	For time.Duration
+	// Notice before shutdown, the notification could not be snoozed, defaults to 1m
+	Notice time.Duration
+	// PollInterval of reading temperatures, defaults to 10s
+	PollInterval time.Duration
+}
+
+// Thermal reads temperatures of sensors in degree Celsius by sensor name
+type Thermal interface {
+	Temperatures() (map[string]float64, error)
+}
+
+// WithThermal option to allow passing of custom thermal sensors, used when Config.Thermal is set
+func WithThermal(t Thermal) option {
+	return func(s *Scheduler) {
+		s.thermal = t
+	}
+}
+
+// scheduleThermalJob to poll temperatures, the job is removed when thermal config is not set.
+// The overheated state is reset only when the config is changed from previous
+func (s *Scheduler) scheduleThermalJob(previous ThermalConfig) error {
+	if s.config.Thermal != previous {
+		s.overheatedSince = time.Time{}
+		s.overheated = false
+	}
+	interval := s.config.Thermal.PollInterval
+	if interval <= 0 {
+		interval = defaultThermalPollInterval
+	}
+	return s.scheduleMonitorJob(thermalTag, s.config.Thermal.ShutdownAbove > 0, interval, s.checkThermal)
+}
+
+// checkThermal begins non-snoozable shutdown when any sensor stays above threshold, it is not restored after cooling down
+func (s *Scheduler) checkThermal() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if s.overheated {
+		return
+	}
+	temps, err := s.thermal.Temperatures()
+	if err != nil {
+		s.logEntry(thermalTag).WithError(err).Error("failed to read temperatures")
+		return
+	}
+	config := s.config.Thermal
+	sensor, temp := hottestSensor(temps)
+	log := s.logEntry(thermalTag).WithField("sensor", sensor).WithField("temperature", temp)
+	if temp <= config.ShutdownAbove {
+		if !s.overheatedSince.IsZero() {
+			log.Info("temperature is back below threshold")
+		}
+		s.overheatedSince = time.Time{}
+		return
+	}
+	if s.overheatedSince.IsZero() {
+		s.overheatedSince = s.now()
+		log.Warn("temperature is above threshold")
+	}
+	duration := config.For
+	if duration <= 0 {
+		duration = defaultThermalFor
+	}
+	if s.now().Sub(s.overheatedSince) < duration {
+		return
+	}
+	s.overheated = true
+	reason := fmt.Sprintf("overheated, %v at %.1f°C", sensor, temp)
+	log.WithField("reason", reason).Warn("thermal shutdown triggered")
+	notice := config.Notice
+	if notice <= 0 {
+		notice = defaultThermalNotice
+	}
+	err = s.trigger(ShutdownRequest{
+		Reason:   reason,
+		Source:   SourceThermal,
+		Deadline: s.now().Add(notice),
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to reschedule shutdown")
+	}
+}
+
+// hottestSensor by temperature, ties are broken by sensor name for stable output
+func hottestSensor(temps map[string]float64) (string, float64) {
+	names := make([]string, 0, len(temps))
+	for name := range temps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var hottest string
+	for _, name := range names {
+		if hottest == "" || temps[name] > temps[hottest] {
+			hottest = name
+		}
+	}
+	return hottest, temps[hottest]
+}
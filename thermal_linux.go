@@ -0,0 +1,65 @@
+//go:build linux
+
+package shutd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+)
+
+// sysfsThermal reads temperatures of thermal zones and hwmon sensors from sysfs
+type sysfsThermal struct {
+	root string
+}
+
+// NewSysfsThermal creates Thermal reading <root>/sys/class/thermal and <root>/sys/class/hwmon, root is / except for testing
+func NewSysfsThermal(root string) Thermal {
+	return sysfsThermal{root: root}
+}
+
+func defaultThermal() Thermal {
+	return NewSysfsThermal("/")
+}
+
+func (t sysfsThermal) Temperatures() (map[string]float64, error) {
+	temps := make(map[string]float64)
+	zones, _ := filepath.Glob(filepath.Join(t.root, "sys", "class", "thermal", "thermal_zone*"))
+	for _, zone := range zones {
+		temp, ok := readMilliCelsius(filepath.Join(zone, "temp"))
+		if !ok {
+			continue
+		}
+		name := readSysfs(zone, "type")
+		if name == "" {
+			name = filepath.Base(zone)
+		}
+		temps[filepath.Base(zone)+"/"+name] = temp
+	}
+	inputs, _ := filepath.Glob(filepath.Join(t.root, "sys", "class", "hwmon", "hwmon*", "temp*_input"))
+	for _, input := range inputs {
+		temp, ok := readMilliCelsius(input)
+		if !ok {
+			continue
+		}
+		dir := filepath.Dir(input)
+		name := readSysfs(dir, "name")
+		if name == "" {
+			name = filepath.Base(dir)
+		}
+		temps[filepath.Base(dir)+"/"+name+"/"+filepath.Base(input)] = temp
+	}
+	if len(temps) == 0 {
+		return nil, fmt.Errorf("no temperature sensor found in %v", filepath.Join(t.root, "sys", "class"))
+	}
+	return temps, nil
+}
+
+// readMilliCelsius of sysfs temperature file in degree Celsius
+func readMilliCelsius(path string) (float64, bool) {
+	v, err := strconv.Atoi(readSysfs(filepath.Dir(path), filepath.Base(path)))
+	if err != nil {
+		return 0, false
+	}
+	return float64(v) / 1000, true
+}
@@ -0,0 +1,38 @@
+package shutd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSysfs(t *testing.T, dir string, attrs map[string]string) {
+	err := os.MkdirAll(dir, 0755)
+	assert.NoError(t, err)
+	for k, v := range attrs {
+		err = os.WriteFile(filepath.Join(dir, k), []byte(v+"\n"), 0644)
+		assert.NoError(t, err)
+	}
+}
+
+func TestSysfsThermal(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, filepath.Join(root, "sys", "class", "thermal", "thermal_zone0"), map[string]string{"type": "x86_pkg_temp", "temp": "45000"})
+	writeSysfs(t, filepath.Join(root, "sys", "class", "thermal", "thermal_zone1"), map[string]string{"type": "acpitz", "temp": "invalid"})
+	writeSysfs(t, filepath.Join(root, "sys", "class", "hwmon", "hwmon0"), map[string]string{"name": "nvme", "temp1_input": "38850"})
+
+	temps, err := NewSysfsThermal(root).Temperatures()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{
+		"thermal_zone0/x86_pkg_temp": 45,
+		"hwmon0/nvme/temp1_input":    38.85,
+	}, temps)
+}
+
+func TestSysfsThermalWithoutSensor(t *testing.T) {
+	root := t.TempDir()
+	_, err := NewSysfsThermal(root).Temperatures()
+	assert.EqualError(t, err, "no temperature sensor found in "+filepath.Join(root, "sys", "class"))
+}
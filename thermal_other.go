@@ -0,0 +1,15 @@
+//go:build !linux
+
+package shutd
+
+import "fmt"
+
+type unsupportedThermal struct{}
+
+func defaultThermal() Thermal {
+	return unsupportedThermal{}
+}
+
+func (unsupportedThermal) Temperatures() (map[string]float64, error) {
+	return nil, fmt.Errorf("thermal sensors are not supported on this platform")
+}
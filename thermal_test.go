@@ -0,0 +1,156 @@
+package shutd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeThermal struct {
+	mu    sync.Mutex
+	temps map[string]float64
+}
+
+func (f *fakeThermal) Temperatures() (map[string]float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.temps, nil
+}
+
+func (f *fakeThermal) set(temps map[string]float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.temps = temps
+}
+
+func TestSustainedOverheatTriggersShutdown(t *testing.T) {
+	thermal := &fakeThermal{temps: map[string]float64{"cpu": 60, "gpu": 70}}
+	texts := make(chan string, 1)
+	notifier := NotifierFunc(func(ctx context.Context, title, text string) (bool, error) {
+		texts <- text
+		return false, nil
+	})
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	config.Notification.Notifier = "test"
+	config.Thermal = ThermalConfig{ShutdownAbove: 90, For: 200 * time.Millisecond, Notice: time.Minute, PollInterval: 50 * time.Millisecond}
+	s, err := getSchedulerWithConfig(t, config, WithThermal(thermal), WithNotifier("test", notifier), WithSnoozeNotificationTask(newNotificationSnoozeTask()))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+
+	overheated := time.Now()
+	thermal.set(map[string]float64{"cpu": 60, "gpu": 95})
+
+	timeout := time.After(2 * time.Second)
+	for {
+		var e Event
+		select {
+		case e = <-events:
+		case <-timeout:
+			t.Fatal("thermal shutdown should be triggered")
+		}
+		if e.Type != ShutdownScheduled {
+			continue
+		}
+		assert.True(t, time.Since(overheated) >= config.Thermal.For)
+		assert.Equal(t, "overheated, gpu at 95.0°C", e.Reason)
+		assert.Equal(t, "overheated, gpu at 95.0°C", newWebhookPayload(e).Reason)
+		break
+	}
+	select {
+	case text := <-texts:
+		assert.Equal(t, "Shutdown in 1 minutes (overheated, gpu at 95.0°C).", text)
+	case <-time.After(2 * time.Second):
+		t.Fatal("notification should be shown")
+	}
+	assert.ErrorIs(t, s.Snooze(), ErrNotSnoozable)
+}
+
+func TestThermalShutdownWhilePausedIsInHistory(t *testing.T) {
+	thermal := &fakeThermal{temps: map[string]float64{"cpu": 60}}
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		return errors.New("poweroff failed")
+	}
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	config.Thermal = ThermalConfig{ShutdownAbove: 90, For: 100 * time.Millisecond, Notice: time.Minute, PollInterval: 50 * time.Millisecond}
+	s, err := getSchedulerWithConfig(t, config, WithThermal(thermal), WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+	assert.NoError(t, s.Pause())
+
+	thermal.set(map[string]float64{"cpu": 95})
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Before(time.Now().Add(2 * time.Minute))
+	})
+	// run the job now instead of waiting for the notice
+	s.runShutdownJob()
+
+	history := s.History()
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, ShutdownStarted, history[0].Type)
+		assert.Equal(t, ShutdownFailed, history[1].Type)
+		for _, e := range history {
+			assert.Equal(t, SourceThermal, e.Source)
+			assert.Equal(t, "overheated, cpu at 95.0°C", e.Reason)
+		}
+		assert.EqualError(t, history[1].Err, "poweroff failed")
+	}
+}
+
+func TestShortOverheatDoesNotTriggerShutdown(t *testing.T) {
+	thermal := &fakeThermal{temps: map[string]float64{"cpu": 95}}
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	s, err := getSchedulerWithConfig(t, config, WithThermal(thermal))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+
+	config.Thermal = ThermalConfig{ShutdownAbove: 90, For: 300 * time.Millisecond, PollInterval: 50 * time.Millisecond}
+	err = s.Configure(config)
+	assert.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	thermal.set(map[string]float64{"cpu": 80})
+
+	timeout := time.After(500 * time.Millisecond)
+	for {
+		select {
+		case e := <-events:
+			assert.Equal(t, config.StartTime, e.ShutdownTime.Format("15:04"))
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func TestThermalShutdownIsKeptOnConfigure(t *testing.T) {
+	thermal := &fakeThermal{temps: map[string]float64{"cpu": 95}}
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	config.Thermal = ThermalConfig{ShutdownAbove: 90, For: time.Nanosecond, Notice: time.Minute, PollInterval: time.Hour}
+	s, err := getSchedulerWithConfig(t, config, WithThermal(thermal))
+	assert.NoError(t, err)
+
+	s.checkThermal()
+	s.checkThermal()
+	assert.Equal(t, SourceThermal, s.ShutdownRequest().Source)
+	triggered, _ := s.ShutdownTime()
+
+	config.SnoozeInterval = 20 * time.Minute
+	assert.NoError(t, s.Configure(config))
+	r := s.ShutdownRequest()
+	assert.Equal(t, SourceThermal, r.Source)
+	assert.False(t, r.Snoozable)
+	assert.True(t, triggered.Equal(r.Deadline), "thermal shutdown should be kept at %v, got %v", triggered, r.Deadline)
+
+	config.Thermal.ShutdownAbove = 100
+	assert.NoError(t, s.Configure(config))
+	assert.Equal(t, SourceScheduled, s.ShutdownRequest().Source)
+	assert.False(t, s.overheated)
+}
+
+func TestHottestSensor(t *testing.T) {
+	sensor, temp := hottestSensor(map[string]float64{"b": 50, "a": 50, "c": 40})
+	assert.Equal(t, "a", sensor)
+	assert.Equal(t, 50.0, temp)
+}
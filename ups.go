@@ -0,0 +1,132 @@
+package shutd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	upsTag                 = "ups"
+	defaultUPSPollInterval = 10 * time.Second
+	defaultUPSNotice       = time.Minute
+)
+
+// UPSConfig for shutting down when UPS monitored by Network UPS Tools is on battery
+type UPSConfig struct {
+	// Host of upsd server, empty to disable
+	Host string
+	// Port of upsd server, defaults to 3493
+	Port int
+	// Name of the UPS on upsd server
+	Name     string
+	Username string
+	Password string
+	// ShutdownAfter on battery for the duration, 0 to shut down only on low battery
+	ShutdownAfter time.Duration
+	// Notice before shutdown, the notification could not be snoozed, defaults to 1m
+	Notice time.Duration
+	// PollInterval of reading UPS status, defaults to 10s
+	PollInterval time.Duration
+}
+
+func (c UPSConfig) client() NUTClient {
+	port := c.Port
+	if port == 0 {
+		port = defaultNUTPort
+	}
+	return NUTClient{
+		Addr:     net.JoinHostPort(c.Host, strconv.Itoa(port)),
+		Username: c.Username,
+		Password: c.Password,
+	}
+}
+
+// scheduleUPSJob to poll UPS status, the job is removed when UPS config is not set.
+// The on battery state is reset only when the config is changed from previous
+func (s *Scheduler) scheduleUPSJob(previous UPSConfig) error {
+	if s.config.UPS != previous {
+		s.upsOnBatterySince = time.Time{}
+		s.upsTriggered = false
+	}
+	interval := s.config.UPS.PollInterval
+	if interval <= 0 {
+		interval = defaultUPSPollInterval
+	}
+	return s.scheduleMonitorJob(upsTag, s.config.UPS.Host != "", interval, s.checkUPS)
+}
+
+// checkUPS begins non-snoozable shutdown on low battery or after on battery for a while, and restores the schedule once back on line power
+// unless the pending shutdown is requested by others
+func (s *Scheduler) checkUPS() {
+	config := s.Config().UPS
+	// upsd is read without lock held, as it could take until timeout
+	status, err := config.client().Status(config.Name)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if err != nil {
+		s.logEntry(upsTag).WithError(err).Error("failed to read UPS status")
+		return
+	}
+	log := s.logEntry(upsTag).WithField("status", status)
+	onBattery, lowBattery := hasFlag(status, "OB"), hasFlag(status, "LB")
+	if !onBattery {
+		s.upsOnBatterySince = time.Time{}
+		if s.upsTriggered {
+			s.upsTriggered = false
+			// other requests are kept, e.g. one-off shutdown or emergency shutdown triggered meanwhile
+			if s.request.Source != SourceUPS || s.batteryLow || s.overheated {
+				return
+			}
+			log.Info("UPS is back on line power: shutdown schedule restored")
+			err = s.restoreSchedule()
+			if err != nil {
+				log.WithError(err).Error("failed to reschedule shutdown")
+			}
+		}
+		return
+	}
+	if s.upsOnBatterySince.IsZero() {
+		s.upsOnBatterySince = s.now()
+		log.Info("UPS is on battery")
+	}
+	if s.upsTriggered {
+		return
+	}
+	var reason string
+	switch {
+	case lowBattery:
+		reason = "UPS battery low"
+	case config.ShutdownAfter > 0 && s.now().Sub(s.upsOnBatterySince) >= config.ShutdownAfter:
+		reason = fmt.Sprintf("UPS on battery for %v", formatMinutes(config.ShutdownAfter))
+	default:
+		return
+	}
+	s.upsTriggered = true
+	log.WithField("reason", reason).Info("UPS shutdown triggered")
+	notice := config.Notice
+	if notice <= 0 {
+		notice = defaultUPSNotice
+	}
+	err = s.trigger(ShutdownRequest{
+		Reason:   reason,
+		Source:   SourceUPS,
+		Deadline: s.now().Add(notice),
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to reschedule shutdown")
+	}
+}
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package shutd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getUPSConfig(d *fakeUPSD) Config {
+	config := getConfigWithShutdownTime(time.Now().Add(12 * time.Hour).Format("15:04"))
+	config.UPS = UPSConfig{
+		Host:         "127.0.0.1",
+		Port:         d.port(),
+		Name:         "ups",
+		Notice:       time.Minute,
+		PollInterval: 50 * time.Millisecond,
+	}
+	return config
+}
+
+func TestUPSLowBatteryTriggersUnsnoozableShutdown(t *testing.T) {
+	d := startFakeUPSD(t, "ups", "OL")
+	texts := make(chan string, 1)
+	notifier := NotifierFunc(func(ctx context.Context, title, text string) (bool, error) {
+		texts <- text
+		return true, nil
+	})
+	config := getUPSConfig(d)
+	config.Notification.Notifier = "test"
+	s, err := getSchedulerWithConfig(t, config, WithNotifier("test", notifier), WithSnoozeNotificationTask(newNotificationSnoozeTask()))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+
+	d.setStatus("OB LB")
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Before(time.Now().Add(config.UPS.Notice + time.Second))
+	})
+
+	select {
+	case text := <-texts:
+		assert.Equal(t, "Shutdown in 1 minutes (UPS battery low).", text)
+	case <-time.After(2 * time.Second):
+		t.Fatal("notification should be shown")
+	}
+	assert.ErrorIs(t, s.Snooze(), ErrNotSnoozable)
+
+	d.setStatus("OL CHRG")
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Format("15:04") == config.StartTime
+	})
+	assert.NoError(t, s.Snooze())
+}
+
+func TestUPSShutdownAfterOnBattery(t *testing.T) {
+	d := startFakeUPSD(t, "ups", "OL")
+	config := getUPSConfig(d)
+	config.UPS.ShutdownAfter = 200 * time.Millisecond
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+	events := s.Subscribe()
+
+	d.setStatus("OB")
+	onBattery := time.Now()
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Before(time.Now().Add(config.UPS.Notice + time.Second))
+	})
+	assert.True(t, time.Since(onBattery) >= config.UPS.ShutdownAfter)
+	assert.Equal(t, ShutdownRequest{Reason: "UPS on battery for 200ms", Source: SourceUPS, Deadline: s.shutdownJob.ScheduledTime()}, s.ShutdownRequest())
+}
+
+func TestUPSShutdownWhilePaused(t *testing.T) {
+	d := startFakeUPSD(t, "ups", "OL")
+	requests := make(chan ShutdownRequest, 1)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		requests <- r
+		return nil
+	}
+	s, err := getSchedulerWithConfig(t, getUPSConfig(d), WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+	events := s.Subscribe()
+	assert.NoError(t, s.Pause())
+
+	d.setStatus("OB LB")
+	waitShutdownScheduled(t, events, func(shutdownTime time.Time) bool {
+		return shutdownTime.Before(time.Now().Add(2 * time.Minute))
+	})
+	// run the job now instead of waiting for the notice
+	s.runShutdownJob()
+	select {
+	case r := <-requests:
+		assert.Equal(t, SourceUPS, r.Source)
+		assert.Equal(t, "UPS battery low", r.Reason)
+	default:
+		t.Fatal("shutdownTask should be called while paused")
+	}
+	assert.True(t, s.Paused())
+}
+
+func TestUPSBackOnLineKeepsOneOffShutdown(t *testing.T) {
+	d := startFakeUPSD(t, "ups", "OL")
+	config := getUPSConfig(d)
+	config.UPS.PollInterval = time.Hour
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+
+	at := time.Now().Add(30 * time.Second).Truncate(time.Second)
+	assert.NoError(t, s.ShutdownAt(at))
+	d.setStatus("OB LB")
+	s.checkUPS()
+	assert.True(t, s.upsTriggered)
+
+	d.setStatus("OL CHRG")
+	s.checkUPS()
+	assert.False(t, s.upsTriggered)
+	assert.Equal(t, SourceOneOff, s.ShutdownRequest().Source)
+	shutdownTime, _ := s.ShutdownTime()
+	assert.True(t, at.Equal(shutdownTime), "one-off shutdown should be kept at %v, got %v", at, shutdownTime)
+}
@@ -0,0 +1,57 @@
+package shutd
+
+import (
+	"time"
+)
+
+// Waker sets wake alarm to power on the computer at given time
+type Waker interface {
+	SetWakeAlarm(t time.Time) error
+}
+
+// WithWaker option to allow passing of custom waker, used when Config.WakeTime is set
+func WithWaker(w Waker) option {
+	return func(s *Scheduler) {
+		s.waker = w
+	}
+}
+
+// WakeTime get next wake time after the next shutdown, returns false if wake time is not configured
+func (s *Scheduler) WakeTime() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shutdownJob == nil {
+		return time.Time{}, false
+	}
+	return s.wakeTimeAfter(s.scheduledTime())
+}
+
+func (s *Scheduler) wakeTimeAfter(shutdownTime time.Time) (time.Time, bool) {
+	if s.config.WakeTime == "" {
+		return time.Time{}, false
+	}
+	clock, err := parseClockTime(s.config.WakeTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return nextClockTime(shutdownTime, clock), true
+}
+
+// setWakeAlarm for the configured wake time before shutdown, scheduled time of the job may already be moved to next day when triggered
+func (s *Scheduler) setWakeAlarm(shutdownTime time.Time) {
+	wakeTime, ok := s.wakeTimeAfter(shutdownTime)
+	if !ok {
+		return
+	}
+	log := s.logEntry(shutdownTag).WithField("wakeTime", wakeTime.Format(time.RFC3339))
+	if s.config.DryRun {
+		log.Info("dry run: skipped setting wake alarm")
+		return
+	}
+	err := s.waker.SetWakeAlarm(wakeTime)
+	if err != nil {
+		log.WithError(err).Error("failed to set wake alarm")
+		return
+	}
+	log.Info("wake alarm set")
+}
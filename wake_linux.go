@@ -0,0 +1,39 @@
+//go:build linux
+
+package shutd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const defaultWakeAlarmPath = "/sys/class/rtc/rtc0/wakealarm"
+
+// rtcWaker sets wake alarm via RTC sysfs interface
+type rtcWaker struct {
+	path string
+}
+
+// NewRTCWaker creates Waker writing to RTC wakealarm file at path, e.g. /sys/class/rtc/rtc0/wakealarm
+func NewRTCWaker(path string) Waker {
+	return rtcWaker{path: path}
+}
+
+func defaultWaker() Waker {
+	return NewRTCWaker(defaultWakeAlarmPath)
+}
+
+func (w rtcWaker) SetWakeAlarm(t time.Time) error {
+	// existing alarm has to be cleared before setting the new one
+	err := os.WriteFile(w.path, []byte("0"), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to clear wake alarm: %w", err)
+	}
+	err = os.WriteFile(w.path, []byte(strconv.FormatInt(t.Unix(), 10)), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write wake alarm: %w", err)
+	}
+	return nil
+}
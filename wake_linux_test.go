@@ -0,0 +1,30 @@
+package shutd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRTCWaker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wakealarm")
+	w := NewRTCWaker(path)
+	wakeTime := time.Date(2026, 10, 20, 7, 30, 0, 0, time.Local)
+
+	err := w.SetWakeAlarm(wakeTime)
+	assert.NoError(t, err)
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, strconv.FormatInt(wakeTime.Unix(), 10), string(b))
+}
+
+func TestRTCWakerWithMissingDevice(t *testing.T) {
+	w := NewRTCWaker(filepath.Join(t.TempDir(), "missing", "wakealarm"))
+	err := w.SetWakeAlarm(time.Now())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to clear wake alarm")
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !windows
+
+package shutd
+
+import (
+	"fmt"
+	"time"
+)
+
+type unsupportedWaker struct{}
+
+func defaultWaker() Waker {
+	return unsupportedWaker{}
+}
+
+func (unsupportedWaker) SetWakeAlarm(t time.Time) error {
+	return fmt.Errorf("wake alarm is not supported on this platform")
+}
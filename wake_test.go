@@ -0,0 +1,80 @@
+package shutd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type wakerFunc func(t time.Time) error
+
+func (f wakerFunc) SetWakeAlarm(t time.Time) error {
+	return f(t)
+}
+
+func TestWakeTime(t *testing.T) {
+	config := getDefaultConfig()
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+	_, ok := s.WakeTime()
+	assert.False(t, ok)
+
+	config.WakeTime = "07:30"
+	err = s.Configure(config)
+	assert.NoError(t, err)
+	wakeTime, ok := s.WakeTime()
+	assert.True(t, ok)
+	shutdownTime, _ := s.ShutdownTime()
+	assert.Equal(t, shutdownTime.Add(7*time.Hour+30*time.Minute), wakeTime)
+}
+
+func TestConfigureWithInvalidWakeTime(t *testing.T) {
+	s := getScheduler(t)
+	config := getDefaultConfig()
+	config.WakeTime = "7am"
+	err := s.Configure(config)
+	assert.EqualError(t, err, `invalid wakeTime "7am": the given time format is not supported`)
+}
+
+func TestWakeAlarmSetBeforeShutdown(t *testing.T) {
+	woken := make(chan time.Time, 1)
+	waker := wakerFunc(func(t time.Time) error {
+		woken <- t
+		return nil
+	})
+	shutdownTime := time.Now().Add(1 * time.Second)
+	config := getConfigWithShutdownTime(shutdownTime.Format("15:04:05"))
+	config.WakeTime = "07:30"
+	_, err := getSchedulerWithConfig(t, config, WithWaker(waker))
+	assert.NoError(t, err)
+
+	select {
+	case wakeTime := <-woken:
+		assert.Equal(t, "07:30", wakeTime.Format("15:04"))
+		assert.True(t, wakeTime.After(shutdownTime))
+		assert.True(t, wakeTime.Before(shutdownTime.Add(24*time.Hour)))
+	case <-time.After(2 * time.Second):
+		t.Fatal("wake alarm should be set")
+	}
+}
+
+func TestNotificationShowsWakeTime(t *testing.T) {
+	asked := make(chan string, 1)
+	notifier := NotifierFunc(func(ctx context.Context, title, text string) (bool, error) {
+		asked <- text
+		return false, nil
+	})
+	config := getDefaultConfig()
+	config.WakeTime = "07:30"
+	config.Notification.Notifier = "test"
+	s, err := getSchedulerWithConfig(t, config, WithNotifier("test", notifier))
+	assert.NoError(t, err)
+
+	err = newNotificationSnoozeTask()(s, s.ShutdownRequest())
+	assert.NoError(t, err)
+	text := <-asked
+	assert.True(t, strings.HasSuffix(text, "Wakes at 07:30"), text)
+}
@@ -0,0 +1,65 @@
+//go:build windows
+
+package shutd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+const wakeTaskName = "shutd wake"
+
+// taskWaker registers one-off scheduled task with WakeToRun, so the wake timer survives shutdown of this process
+type taskWaker struct{}
+
+func defaultWaker() Waker {
+	return taskWaker{}
+}
+
+func (taskWaker) SetWakeAlarm(t time.Time) error {
+	f, err := os.CreateTemp("", "shutd-wake-*.xml")
+	if err != nil {
+		return fmt.Errorf("failed to create wake task definition: %w", err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(wakeTaskXML(t))
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write wake task definition: %w", err)
+	}
+	out, err := exec.Command("schtasks", "/create", "/f", "/tn", wakeTaskName, "/xml", filepath.Clean(f.Name())).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to create wake task: %w: %s", err, out)
+	}
+	return nil
+}
+
+func wakeTaskXML(t time.Time) string {
+	return fmt.Sprintf(`<?xml version="1.0"?>
+<Task version="1.2" xmlns="http://schemas.microsoft.com/windows/2004/02/mit/task">
+  <Triggers>
+    <TimeTrigger>
+      <StartBoundary>%v</StartBoundary>
+      <EndBoundary>%v</EndBoundary>
+      <Enabled>true</Enabled>
+    </TimeTrigger>
+  </Triggers>
+  <Settings>
+    <WakeToRun>true</WakeToRun>
+    <DeleteExpiredTaskAfter>PT0S</DeleteExpiredTaskAfter>
+    <StartWhenAvailable>false</StartWhenAvailable>
+  </Settings>
+  <Actions>
+    <Exec>
+      <Command>cmd</Command>
+      <Arguments>/C exit</Arguments>
+    </Exec>
+  </Actions>
+</Task>
+`, t.Format("2006-01-02T15:04:05"), t.Add(time.Hour).Format("2006-01-02T15:04:05"))
+}
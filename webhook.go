@@ -0,0 +1,217 @@
+package shutd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultWebhookTimeout = 5 * time.Second
+	defaultWebhookRetries = 3
+	defaultWebhookBackoff = time.Second
+	// shutdownWebhookWait is the longest delay of shutdown for delivering shutdown_started, retries are not waited for
+	shutdownWebhookWait = 2 * time.Second
+)
+
+// WebhookConfig for posting events to URL
+type WebhookConfig struct {
+	URL string
+	// Events to post, empty to post all events
+	Events []EventType
+	// Headers of the request, Content-Type defaults to application/json
+	Headers map[string]string
+	// Template of request body in text/template with WebhookPayload, empty to post WebhookPayload as JSON
+	Template string
+	// Timeout of each attempt, defaults to 5s
+	Timeout time.Duration
+	// Retries after failed attempt, defaults to 3
+	Retries *int
+	// Backoff before first retry, doubled on each retry, defaults to 1s
+	Backoff time.Duration
+}
+
+// WebhookPayload posted to webhooks
+type WebhookPayload struct {
+	Event        EventType `json:"event"`
+	Host         string    `json:"host"`
+	Time         time.Time `json:"time"`
+	ShutdownTime time.Time `json:"shutdownTime"`
+	DryRun       bool      `json:"dryRun"`
+	SnoozeCount  int       `json:"snoozeCount"`
+	Reason       string    `json:"reason,omitempty"`
+	Source       string    `json:"source"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// webhook of config with its template parsed once by Configure
+type webhook struct {
+	WebhookConfig
+	template *template.Template
+}
+
+// newWebhooks of configs, invalid template is reported as ConfigError
+func newWebhooks(configs []WebhookConfig) ([]webhook, error) {
+	webhooks := make([]webhook, len(configs))
+	for i, c := range configs {
+		webhooks[i].WebhookConfig = c
+		if c.Template == "" {
+			continue
+		}
+		t, err := template.New("webhook").Parse(c.Template)
+		if err != nil {
+			return nil, &ConfigError{Field: "webhooks.template", Value: c.Template, Err: err}
+		}
+		webhooks[i].template = t
+	}
+	return webhooks, nil
+}
+
+// deliverWebhooks in background as tasks cancelled on close, the returned channel is closed once all deliveries are done.
+// It is called with lock held
+func (s *Scheduler) deliverWebhooks(e Event) <-chan struct{} {
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for _, w := range s.webhooks {
+		if !w.accepts(e.Type) || !s.beginTask() {
+			continue
+		}
+		wg.Add(1)
+		go func(w webhook) {
+			defer s.tasks.Done()
+			defer wg.Done()
+			err := w.deliver(s.ctx, newWebhookPayload(e))
+			if err != nil && s.ctx.Err() == nil {
+				s.logger.WithError(err).WithField("event", e.Type).WithField("webhook", w.URL).Error("failed to deliver webhook")
+			}
+		}(w)
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	return done
+}
+
+// webhookTimeout is the longest timeout of webhooks posting events of type t, 0 without such webhooks
+func (s *Scheduler) webhookTimeout(t EventType) time.Duration {
+	var longest time.Duration
+	for _, w := range s.webhooks {
+		if !w.accepts(t) {
+			continue
+		}
+		timeout := w.Timeout
+		if timeout <= 0 {
+			timeout = defaultWebhookTimeout
+		}
+		if timeout > longest {
+			longest = timeout
+		}
+	}
+	return longest
+}
+
+func newWebhookPayload(e Event) WebhookPayload {
+	host, _ := os.Hostname()
+	p := WebhookPayload{
+		Event:        e.Type,
+		Host:         host,
+		Time:         e.Time,
+		ShutdownTime: e.ShutdownTime,
+		DryRun:       e.DryRun,
+		SnoozeCount:  e.SnoozeCount,
+		Reason:       e.Reason,
+		Source:       string(e.Source),
+	}
+	if e.Err != nil {
+		p.Error = e.Err.Error()
+	}
+	return p
+}
+
+func (w WebhookConfig) accepts(t EventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (w webhook) body(p WebhookPayload) ([]byte, error) {
+	if w.template == nil {
+		return json.Marshal(p)
+	}
+	var b bytes.Buffer
+	err := w.template.Execute(&b, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute webhook template: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// deliver payload with retries and exponential backoff until ctx is done
+func (w webhook) deliver(ctx context.Context, p WebhookPayload) error {
+	body, err := w.body(p)
+	if err != nil {
+		return err
+	}
+	retries := defaultWebhookRetries
+	if w.Retries != nil {
+		retries = *w.Retries
+	}
+	backoff := w.Backoff
+	if backoff <= 0 {
+		backoff = defaultWebhookBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		err = w.post(ctx, body)
+		if err == nil || attempt >= retries {
+			return err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+func (w WebhookConfig) post(ctx context.Context, body []byte) error {
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected webhook response: %v", strings.TrimSpace(resp.Status))
+	}
+	return nil
+}
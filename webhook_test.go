@@ -0,0 +1,209 @@
+package shutd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookServer(t *testing.T, handler func(w http.ResponseWriter)) (*httptest.Server, chan webhookRequest) {
+	requests := make(chan webhookRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		requests <- webhookRequest{header: r.Header, body: body}
+		handler(w)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func receiveWebhook(t *testing.T, requests chan webhookRequest) webhookRequest {
+	select {
+	case r := <-requests:
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook should be posted")
+	}
+	return webhookRequest{}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestWebhookPostsSnoozedEvent(t *testing.T) {
+	server, requests := newWebhookServer(t, func(w http.ResponseWriter) {})
+	config := getDefaultConfig()
+	config.Webhooks = []WebhookConfig{{URL: server.URL, Events: []EventType{Snoozed}}}
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+
+	err = s.Snooze()
+	assert.NoError(t, err)
+
+	r := receiveWebhook(t, requests)
+	assert.Equal(t, "application/json", r.header.Get("Content-Type"))
+	var payload WebhookPayload
+	assert.NoError(t, json.Unmarshal(r.body, &payload))
+	assert.Equal(t, Snoozed, payload.Event)
+	assert.Equal(t, 1, payload.SnoozeCount)
+	assert.Equal(t, "00:15", payload.ShutdownTime.Format("15:04"))
+
+	select {
+	case r := <-requests:
+		t.Fatalf("only snoozed event should be posted, got %s", r.body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebhookWithTemplateAndHeaders(t *testing.T) {
+	server, requests := newWebhookServer(t, func(w http.ResponseWriter) {})
+	config := getDefaultConfig()
+	config.Webhooks = []WebhookConfig{{
+		URL:      server.URL,
+		Events:   []EventType{ShutdownScheduled},
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		Template: `{"text": "{{.Event}} at {{.ShutdownTime.Format "15:04"}}"}`,
+	}}
+	_, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+
+	r := receiveWebhook(t, requests)
+	assert.Equal(t, "Bearer token", r.header.Get("Authorization"))
+	assert.Equal(t, `{"text": "scheduled at 00:00"}`, string(r.body))
+}
+
+func TestWebhookRetriesWithBackoff(t *testing.T) {
+	var attempts int32
+	server, requests := newWebhookServer(t, func(w http.ResponseWriter) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	w := webhook{WebhookConfig: WebhookConfig{URL: server.URL, Backoff: 10 * time.Millisecond}}
+	err := w.deliver(context.Background(), WebhookPayload{Event: ShutdownStarted})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	assert.Len(t, requests, 3)
+}
+
+func TestWebhookGivesUpAfterRetries(t *testing.T) {
+	server, _ := newWebhookServer(t, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	w := webhook{WebhookConfig: WebhookConfig{URL: server.URL, Retries: intPtr(1), Backoff: 10 * time.Millisecond}}
+	err := w.deliver(context.Background(), WebhookPayload{Event: ShutdownStarted})
+	assert.EqualError(t, err, "unexpected webhook response: 502 Bad Gateway")
+}
+
+func TestWebhookDoesNotDelayScheduler(t *testing.T) {
+	server, _ := newWebhookServer(t, func(w http.ResponseWriter) {
+		time.Sleep(500 * time.Millisecond)
+	})
+	testLogger, hook := test.NewNullLogger()
+	config := getDefaultConfig()
+	config.Webhooks = []WebhookConfig{{URL: server.URL, Events: []EventType{Snoozed}, Timeout: 50 * time.Millisecond, Retries: intPtr(0)}}
+	s, err := getSchedulerWithConfig(t, config, WithLogger(testLogger))
+	assert.NoError(t, err)
+
+	start := time.Now()
+	err = s.Snooze()
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, "failed to deliver webhook", hook.LastEntry().Message)
+	assert.Equal(t, Snoozed, hook.LastEntry().Data["event"])
+}
+
+func TestShutdownStartedWebhookIsDeliveredBeforeShutdownTask(t *testing.T) {
+	var delivered int32
+	server, _ := newWebhookServer(t, func(w http.ResponseWriter) {
+		time.Sleep(200 * time.Millisecond)
+		atomic.StoreInt32(&delivered, 1)
+	})
+	deliveredBeforeTask := make(chan bool, 1)
+	shutdownTask := func(s *Scheduler, r ShutdownRequest) error {
+		deliveredBeforeTask <- atomic.LoadInt32(&delivered) == 1
+		return nil
+	}
+	config := getDefaultConfig()
+	config.Webhooks = []WebhookConfig{{URL: server.URL, Events: []EventType{ShutdownStarted}}}
+	s, err := getSchedulerWithConfig(t, config, WithShutdownTask(shutdownTask))
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.ShutdownNow())
+	assert.True(t, <-deliveredBeforeTask)
+}
+
+func TestShutdownStartedWebhookWaitIsBoundedByTimeout(t *testing.T) {
+	server, _ := newWebhookServer(t, func(w http.ResponseWriter) {
+		time.Sleep(time.Second)
+	})
+	config := getDefaultConfig()
+	config.Webhooks = []WebhookConfig{{URL: server.URL, Events: []EventType{ShutdownStarted}, Timeout: 100 * time.Millisecond, Retries: intPtr(3)}}
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	assert.NoError(t, s.ShutdownNow())
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestShutdownStartedWebhookWaitIsBoundedWithLongTimeout(t *testing.T) {
+	server, _ := newWebhookServer(t, func(w http.ResponseWriter) {
+		time.Sleep(shutdownWebhookWait + time.Second)
+	})
+	config := getDefaultConfig()
+	config.Webhooks = []WebhookConfig{{URL: server.URL, Events: []EventType{ShutdownStarted}, Timeout: time.Minute}}
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	assert.NoError(t, s.ShutdownNow())
+	assert.Less(t, time.Since(start), shutdownWebhookWait+500*time.Millisecond)
+}
+
+func TestInvalidWebhookTemplate(t *testing.T) {
+	config := getDefaultConfig()
+	config.Webhooks = []WebhookConfig{{URL: "http://localhost", Template: `{{.Event`}}
+	_, err := NewScheduler(config)
+	var configErr *ConfigError
+	if assert.ErrorAs(t, err, &configErr) {
+		assert.Equal(t, "webhooks.template", configErr.Field)
+	}
+}
+
+func TestCloseCancelsWebhookRetries(t *testing.T) {
+	server, requests := newWebhookServer(t, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	config := getDefaultConfig()
+	config.Webhooks = []WebhookConfig{{URL: server.URL, Events: []EventType{Snoozed}, Backoff: time.Hour}}
+	s, err := getSchedulerWithConfig(t, config)
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Snooze())
+	receiveWebhook(t, requests)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, s.Close(ctx), "webhook retries should be cancelled and waited for")
+	for range s.ShutdownTimeChangedChan() {
+		// drained until closed
+	}
+}